@@ -0,0 +1,60 @@
+package main
+
+import (
+	_ "embed"
+	"time"
+)
+
+var (
+	//go:embed adhkar/morning.txt
+	morningAdhkar string
+	//go:embed adhkar/evening.txt
+	eveningAdhkar string
+)
+
+// adhkar texts by name
+var adhkar = map[string]string{
+	"morning": morningAdhkar,
+	"evening": eveningAdhkar,
+}
+
+// AdhkarReminder fires Offset after the After timing, e.g. "Sunrise", showing
+// the Adhkar text ("morning" or "evening"). With Verse the notification shows
+// the verse of the day.
+type AdhkarReminder struct {
+	Name   string
+	After  string
+	Offset Duration
+	Sound  string
+	Adhkar string
+	Verse  bool
+}
+
+var (
+	showAdhkar      = true // show the adhkar text with the reminder
+	adhkarReminders = []AdhkarReminder{
+		{Name: "Morning adhkar", After: "Fajr", Offset: Duration(30 * time.Minute), Adhkar: "morning", Verse: true},
+		{Name: "Evening adhkar", After: "Asr", Offset: Duration(30 * time.Minute), Adhkar: "evening"},
+	}
+)
+
+func adhkarEvents(timings map[string]time.Time) []Event {
+	events := make([]Event, 0, len(adhkarReminders))
+	for _, r := range adhkarReminders {
+		t, ok := timings[r.After]
+		if !ok {
+			continue
+		}
+
+		ev := Event{Name: r.Name, Time: t.Add(time.Duration(r.Offset)), Sound: r.Sound}
+		if showAdhkar {
+			ev.Text = adhkar[r.Adhkar]
+		}
+		if r.Verse && showVerse {
+			v := DailyVerse(t)
+			ev.Message = v.Translation + " (" + v.Source + ")"
+		}
+		events = append(events, ev)
+	}
+	return events
+}
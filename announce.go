@@ -0,0 +1,4 @@
+package main
+
+// Speak the next prayer through the screen reader when it changes.
+var announceNextPrayer = false
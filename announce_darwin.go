@@ -0,0 +1,13 @@
+package main
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+// Announce has VoiceOver speak text. It fails when VoiceOver isn't running
+// or AppleScript control of it isn't allowed.
+func Announce(text string) error {
+	script := `tell application "VoiceOver" to output ` + strconv.Quote(text)
+	return exec.Command("osascript", "-e", script).Run()
+}
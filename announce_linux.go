@@ -0,0 +1,9 @@
+package main
+
+import "os/exec"
+
+// Announce speaks text through Speech Dispatcher, the speech service Orca
+// uses, so it follows the screen reader's voice settings.
+func Announce(text string) error {
+	return exec.Command("spd-say", "--application-name", "Prayer", text).Run()
+}
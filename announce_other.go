@@ -0,0 +1,9 @@
+//go:build !linux && !windows && !darwin
+
+package main
+
+import "errors"
+
+func Announce(text string) error {
+	return errors.New("not supported on this system")
+}
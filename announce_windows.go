@@ -0,0 +1,16 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// Announce speaks text with the system speech synthesizer.
+func Announce(text string) error {
+	script := `Add-Type -AssemblyName System.Speech
+(New-Object System.Speech.Synthesis.SpeechSynthesizer).Speak('` + strings.ReplaceAll(text, "'", "''") + `')`
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	return cmd.Run()
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Announcement is a notice in the signage ticker, shown from Start to End,
+// days like 2006-01-02 that leave it open when empty.
+type Announcement struct {
+	Text  string
+	Start string `json:",omitempty"`
+	End   string `json:",omitempty"`
+}
+
+// Shown reports whether a is shown on the day of now.
+func (a Announcement) Shown(now time.Time) bool {
+	day := now.Format("2006-01-02")
+	return (a.Start == "" || a.Start <= day) && (a.End == "" || day <= a.End)
+}
+
+// Validate checks a has a text and its days are dates.
+func (a Announcement) Validate() error {
+	if a.Text == "" {
+		return fmt.Errorf("announcement has no Text")
+	}
+	for _, d := range []string{a.Start, a.End} {
+		if _, err := time.Parse("2006-01-02", d); d != "" && err != nil {
+			return fmt.Errorf("announcement %q: %q is not a day like 2006-01-02", a.Text, d)
+		}
+	}
+	if a.Start != "" && a.End != "" && a.End < a.Start {
+		return fmt.Errorf("announcement %q ends before it starts", a.Text)
+	}
+	return nil
+}
+
+// ShownAnnouncements returns the texts of the announcements shown at now.
+func ShownAnnouncements(now time.Time) []string {
+	var texts []string
+	for _, a := range signage.Announcements {
+		if a.Shown(now) {
+			texts = append(texts, a.Text)
+		}
+	}
+	return texts
+}
+
+//go:embed announcements.html
+var announcementsPage []byte
+
+// announcementsHandler gets and, with PUT, replaces the announcements of
+// the config file.
+func announcementsHandler(s *Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var list []Announcement
+		var err error
+		switch r.Method {
+		case http.MethodGet:
+			err = call(s, func() { list = append(list, signage.Announcements...) })
+		case http.MethodPut:
+			if err := json.NewDecoder(r.Body).Decode(&list); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			for _, a := range list {
+				if err := a.Validate(); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+			saved := make(chan error, 1)
+			if err = call(s, func() {
+				signage.Announcements = list
+				saved <- SaveConfig()
+			}); err == nil {
+				err = <-saved
+			}
+		default:
+			http.Error(w, "use GET or PUT", http.StatusMethodNotAllowed)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		if list == nil {
+			list = []Announcement{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+	}
+}
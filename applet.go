@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// appletCommand prints the next prayer and today's timings for panel
+// applets, in the line format of Argos, Kargos and BitBar: the panel text
+// with its icon, then the menu entries after "---". It reads the timings
+// itself, so works whether the app or daemon is running or not.
+func appletCommand(args []string) int {
+	fs := flag.NewFlagSet("applet", flag.ExitOnError)
+	dir := fs.String("dir", "", "directory with the timings")
+	fs.Parse(args)
+
+	if err := openForCommand(*dir); err != nil {
+		fmt.Fprintln(os.Stderr, "applet:", err)
+		return 1
+	}
+
+	fmt.Print(AppletText(time.Now()))
+	return 0
+}
+
+// AppletText is the applet output at now.
+func AppletText(now time.Time) string {
+	prayers := PrayerTimings(location, now)
+	next, _ := NextPrayer(location, prayers)
+	timings := DayTimings(location, now)
+
+	var b strings.Builder
+	icon := "appointment-soon"
+	warning := ""
+	for _, p := range MakruhTimes(timings) {
+		if p.Contains(now) {
+			icon, warning = "dialog-warning", FormatMakruh(p)
+		}
+	}
+	fmt.Fprintf(&b, "%s %s | iconName=%s\n", next.Label(), FormatUntil("in", next.Time), icon)
+	b.WriteString("---\n")
+	if warning != "" {
+		fmt.Fprintf(&b, "%s | iconName=dialog-warning\n", warning)
+	}
+	for _, p := range prayers {
+		mark := ""
+		if IsPrayed(p) {
+			mark = " ✓"
+		}
+		fmt.Fprintf(&b, "%s%s | font=monospace\n", p, mark)
+	}
+	b.WriteString("---\n")
+
+	// Started in the same directory, or raising the running app.
+	exe, err := os.Executable()
+	if err != nil {
+		exe = "prayer"
+	}
+	wd, _ := os.Getwd()
+	fmt.Fprintf(&b, "Open Prayer | bash=\"'%s' -dir '%s'\" terminal=false\n", exe, wd)
+	return b.String()
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// The self-signed certificate and key made for the server when TLS is on
+// without a CertFile, kept so clients can trust it once.
+const (
+	selfSignedCert = "server.crt"
+	selfSignedKey  = "server.key"
+)
+
+// validToken reports whether the credentials from a request, as
+// "Bearer <token>" or the bare token, have the server's Token.
+func validToken(credentials string) bool {
+	if server.Token == "" {
+		return true
+	}
+	token := strings.TrimPrefix(credentials, "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(server.Token)) == 1
+}
+
+// requireToken passes the requests with the server's Token on to h, in the
+// Authorization header or, for EventSource which can't set headers, the
+// token parameter.
+func requireToken(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		credentials := r.Header.Get("Authorization")
+		if credentials == "" {
+			credentials = r.URL.Query().Get("token")
+		}
+		if !validToken(credentials) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="prayer"`)
+			http.Error(w, "token required", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// requireRemoteToken refuses the requests changing the server from other
+// machines when there's no Token, so that not anyone on a kiosk's network
+// can manage it.
+func requireRemoteToken(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, _ := net.SplitHostPort(r.RemoteAddr)
+		if r.Method != http.MethodGet && server.Token == "" && !isLoopback(host) {
+			http.Error(w, "a Token is needed to manage the server from other machines", http.StatusForbidden)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// grpcAuthorized checks the authorization metadata of a call has the
+// server's Token.
+func grpcAuthorized(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var credentials string
+	if v := md.Get("authorization"); len(v) > 0 {
+		credentials = v[0]
+	}
+	if !validToken(credentials) {
+		return status.Error(codes.Unauthenticated, "token required")
+	}
+	return nil
+}
+
+// grpcServerOptions returns the options of the gRPC server following the
+// Token, TLS and RateLimit settings.
+func grpcServerOptions(config *tls.Config, l *rateLimiter) []grpc.ServerOption {
+	opts := grpcInterceptors(grpcLimitRate(l), grpcAuthorized)
+	if config != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(config)))
+	}
+	return opts
+}
+
+// serverTLS returns the TLS config of the server, nil when TLS is off.
+func serverTLS() (*tls.Config, error) {
+	if !server.TLS {
+		return nil, nil
+	}
+	certFile, keyFile := server.CertFile, server.KeyFile
+	if certFile == "" {
+		certFile, keyFile = selfSignedCert, selfSignedKey
+		if _, err := os.Stat(certFile); os.IsNotExist(err) {
+			if err := writeSelfSigned(certFile, keyFile); err != nil {
+				return nil, err
+			}
+		}
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, nil
+}
+
+// writeSelfSigned makes a certificate for this machine's names and the
+// hosts of the server's addresses, valid for ten years.
+func writeSelfSigned(certFile, keyFile string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+	tmpl := x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "Prayer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(10, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	if name, err := os.Hostname(); err == nil {
+		tmpl.DNSNames = append(tmpl.DNSNames, name)
+	}
+	for _, addr := range []string{server.Addr, server.GRPCAddr} {
+		host, _, _ := net.SplitHostPort(addr)
+		if ip := net.ParseIP(host); ip != nil && !ip.IsUnspecified() && !ip.IsLoopback() {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else if ip == nil && host != "" && host != "localhost" {
+			tmpl.DNSNames = append(tmpl.DNSNames, host)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return err
+	}
+	return os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+}
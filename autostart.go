@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const appName = "Prayer"
+
+func autostartCommand(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+
+	var err error
+	switch args[0] {
+	case "enable":
+		var cmd []string
+		if cmd, err = autostartCommandLine(); err == nil {
+			err = EnableAutostart(cmd)
+		}
+	case "disable":
+		err = DisableAutostart()
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "autostart:", err)
+		return 1
+	}
+	fmt.Printf("Autostart %sd\n", args[0])
+	return 0
+}
+
+// autostartCommandLine returns the command starting this executable
+// minimized. The current directory is passed along since timings, sounds and
+// the icon are looked up relative to it.
+func autostartCommandLine() ([]string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return nil, err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	return []string{exe, "-minimized", "-dir", wd}, nil
+}
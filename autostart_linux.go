@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// XDG autostart entry, see the Desktop Application Autostart Specification.
+func autostartPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "autostart", "prayer.desktop"), nil
+}
+
+func EnableAutostart(cmd []string) error {
+	path, err := autostartPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	quoted := make([]string, len(cmd))
+	for i, arg := range cmd {
+		quoted[i] = `"` + strings.NewReplacer(`\`, `\\\\`, `"`, `\\"`, "`", "\\\\`", "$", `\\$`).Replace(arg) + `"`
+	}
+
+	entry := fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=%s
+Comment=Prayer times and adhan
+Exec=%s
+Terminal=false
+X-GNOME-Autostart-enabled=true
+`, appName, strings.Join(quoted, " "))
+	return os.WriteFile(path, []byte(entry), 0644)
+}
+
+func DisableAutostart() error {
+	path, err := autostartPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !windows && !darwin
+
+package main
+
+import "errors"
+
+func EnableAutostart(cmd []string) error {
+	return errors.New("not supported on this platform")
+}
+
+func DisableAutostart() error {
+	return errors.New("not supported on this platform")
+}
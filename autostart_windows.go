@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+const runKey = `Software\Microsoft\Windows\CurrentVersion\Run`
+
+func EnableAutostart(cmd []string) error {
+	k, _, err := registry.CreateKey(registry.CURRENT_USER, runKey, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+
+	quoted := make([]string, len(cmd))
+	for i, arg := range cmd {
+		quoted[i] = `"` + arg + `"`
+	}
+	return k.SetStringValue(appName, strings.Join(quoted, " "))
+}
+
+func DisableAutostart() error {
+	k, err := registry.OpenKey(registry.CURRENT_USER, runKey, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+
+	if err := k.DeleteValue(appName); err != nil && err != registry.ErrNotExist {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+//go:build !gio
+
+package main
+
+import (
+	"os"
+
+	"github.com/gen2brain/iup-go/iup"
+)
+
+// backupFile asks for a backup file to open or save. It returns false if
+// the user cancelled.
+func backupFile(dialogType, title string) (string, bool) {
+	fd := iup.FileDlg()
+	defer fd.Destroy()
+	fd.SetAttributes(map[string]string{
+		"DIALOGTYPE": dialogType,
+		"TITLE":      title,
+		"FILTER":     "*.json",
+		"FILTERINFO": "Prayer backup",
+		"FILE":       "prayer-backup.json",
+	})
+	iup.Popup(fd, iup.CENTER, iup.CENTER)
+	if fd.GetInt("STATUS") == -1 {
+		return "", false
+	}
+	return fd.GetAttribute("VALUE"), true
+}
+
+// showBackup opens the backup window. onImport is called after a backup is
+// restored, to pick up the new settings.
+func showBackup(onImport func()) {
+	exportButton := iup.Button("&Export…")
+	iup.SetAttribute(exportButton, "PADDING", pxSize(5, 5))
+	iup.SetCallback(exportButton, "ACTION", iup.ActionFunc(func(ih iup.Ihandle) int {
+		path, ok := backupFile("SAVE", "Export backup")
+		if !ok {
+			return iup.DEFAULT
+		}
+		f, err := os.Create(path)
+		if err == nil {
+			err = ExportBackup(f)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}
+		if err != nil {
+			iup.MessageError(ih, "Export failed: "+err.Error())
+		}
+		return iup.DEFAULT
+	}))
+
+	importButton := iup.Button("&Import…")
+	iup.SetAttribute(importButton, "PADDING", pxSize(5, 5))
+	iup.SetCallback(importButton, "ACTION", iup.ActionFunc(func(ih iup.Ihandle) int {
+		path, ok := backupFile("OPEN", "Import backup")
+		if !ok {
+			return iup.DEFAULT
+		}
+		f, err := os.Open(path)
+		if err == nil {
+			err = ImportBackup(f)
+			f.Close()
+		}
+		if err != nil {
+			iup.MessageError(ih, "Import failed: "+err.Error())
+			return iup.DEFAULT
+		}
+		onImport()
+		return iup.DEFAULT
+	}))
+
+	label := iup.Label("Settings, location profiles, sounds and the prayer log.")
+	vbox := iup.Vbox(label, iup.Hbox(exportButton, importButton), soundsFrame())
+	vbox.SetAttributes(map[string]string{
+		"MARGIN": pxSize(4, 4),
+		"GAP":    scaled(4),
+	})
+
+	dlg := iup.Dialog(vbox)
+	iup.SetAttribute(dlg, "TITLE", "Backup and settings")
+	iup.SetCallback(dlg, "CLOSE_CB", iup.CloseFunc(func(ih iup.Ihandle) int {
+		StopSound()
+		iup.Hide(ih)
+		return iup.IGNORE
+	}))
+	iup.Show(dlg)
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// Bell rings the terminal's bell, which most terminals and consoles turn
+// into the system's, for when the sounds can't be played.
+func Bell() {
+	fmt.Fprint(os.Stdout, "\a")
+}
+
+// soundFailed falls back to the bell and a flash of the window when a
+// sound couldn't be played, so that the alert isn't missed, and shows why.
+// It can be called from any goroutine.
+func (s *Scheduler) soundFailed(err error) {
+	Bell()
+	go s.Do(func() {
+		if s.Flash != nil {
+			s.Flash()
+		}
+		s.showError(fmt.Errorf("sound: %w", err))
+	})
+}
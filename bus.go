@@ -0,0 +1,109 @@
+package main
+
+import "sync"
+
+// Topics published on the Scheduler's Bus.
+const (
+	TimingsUpdated  = "TimingsUpdated"  // the schedule was reloaded or rolled over
+	LocationChanged = "LocationChanged" // the active location changed, with Location
+	ReminderDue     = "ReminderDue"     // a reminder's Alert
+	AdhanDue        = "AdhanDue"        // the adhan's Alert
+	AlertDue        = "AlertDue"        // any other Alert, such as an event or window alert
+	TextDue         = "TextDue"         // an event's Text, titled Alert.Name
+	AdhanFinished   = "AdhanFinished"   // the adhan finished playing, from another goroutine
+)
+
+// busTopics are all the topics, for the integrations following every event.
+var busTopics = []string{TimingsUpdated, LocationChanged, ReminderDue, AdhanDue, AlertDue, TextDue, AdhanFinished}
+
+// BusEvent is what's published on a topic of a Bus.
+type BusEvent struct {
+	Topic    string
+	Alert    Alert
+	Volume   float64 // of Alert.Sound, in powers of two from the file's own
+	Text     string
+	Location Location
+
+	id int64 // of Alert in the history
+}
+
+// Bus passes events to the functions subscribed to their topic, so the
+// frontends, sound player and integrations each follow the scheduler on
+// their own. Subscribers are called in order on the publishing goroutine.
+type Bus struct {
+	// Failed is passed the panics of subscribers, which don't stop the
+	// others. They are logged when it's nil.
+	Failed func(err error)
+
+	mu   sync.Mutex
+	subs map[string][]*func(BusEvent)
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: make(map[string][]*func(BusEvent))}
+}
+
+// Subscribe calls fn with every event published on topic, until the
+// returned function is called.
+func (b *Bus) Subscribe(topic string, fn func(BusEvent)) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	p := &fn
+	b.subs[topic] = append(b.subs[topic], p)
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		subs := b.subs[topic]
+		for i := range subs {
+			if subs[i] == p {
+				b.subs[topic] = append(subs[:i:i], subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish calls the subscribers of ev.Topic.
+func (b *Bus) Publish(ev BusEvent) {
+	b.mu.Lock()
+	subs := b.subs[ev.Topic]
+	b.mu.Unlock()
+
+	for _, fn := range subs {
+		b.call(*fn, ev)
+	}
+}
+
+func (b *Bus) call(fn func(BusEvent), ev BusEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			err := newPanicError(ev.Topic+" subscriber", r)
+			if b.Failed == nil {
+				logError(err)
+				return
+			}
+			b.Failed(err)
+		}
+	}()
+	fn(ev)
+}
+
+// StartIntegrations starts the plugins, scripts and servers following s.
+func StartIntegrations(s *Scheduler) {
+	StartPlugins(s)
+	StartScripts(s)
+	StartStreamDeck(s)
+	StartServer(s)
+}
+
+// alertTopic returns the topic alerts of kind are published on.
+func alertTopic(kind string) string {
+	switch kind {
+	case "Reminder":
+		return ReminderDue
+	case "Adhan":
+		return AdhanDue
+	}
+	return AlertDue
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// How long the downloaded and computed timings are kept: the months before
+// the current one, and the days the timings of a place, or of other
+// calculation settings, are kept after they were last used. 0 keeps them
+// for ever. They are cleaned as each day starts, or with prayer cache
+// clean.
+var cache = struct {
+	KeepMonths       int
+	KeepLocationDays int
+}{
+	KeepMonths:       2,
+	KeepLocationDays: 90,
+}
+
+// cacheFile is a file of timings in timingsDir.
+type cacheFile struct {
+	Path     string
+	Day      time.Time // whose month it has
+	Key      string    // of its place and calculation settings
+	Size     int64
+	Modified time.Time
+}
+
+// cachePaths returns the paths of the files of timings in timingsDir,
+// compressed or not.
+func cachePaths() ([]string, error) {
+	paths, err := filepath.Glob(timingsDir + "timings-*.json")
+	if err != nil {
+		return nil, err
+	}
+	gz, err := filepath.Glob(timingsDir + "timings-*.json.gz")
+	return append(paths, gz...), err
+}
+
+// readTimings reads the file of timings at path, uncompressing it if it's
+// gzipped.
+func readTimings(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.HasSuffix(path, ".gz") {
+		return data, err
+	}
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// writeTimings writes data to the file of timings at path, gzipped, a
+// year of them for a few places being a lot on the small cards of kiosks.
+func writeTimings(path string, data []byte) error {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	w.Write(data)
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return writeAtomic(path, b.Bytes())
+}
+
+// cacheFiles returns the files of timings in timingsDir, by Key and Day.
+func cacheFiles() ([]cacheFile, error) {
+	paths, err := cachePaths()
+	if err != nil {
+		return nil, err
+	}
+	var files []cacheFile
+	for _, path := range paths {
+		name := strings.TrimPrefix(filepath.Base(path), "timings-")
+		name = strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ".json")
+		if len(name) < len(time.DateOnly)+2 {
+			continue
+		}
+		day, err := time.ParseInLocation(time.DateOnly, name[:len(time.DateOnly)], time.Local)
+		if err != nil {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		files = append(files, cacheFile{path, day, name[len(time.DateOnly)+1:], info.Size(), info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Key != files[j].Key {
+			return files[i].Key < files[j].Key
+		}
+		return files[i].Day.Before(files[j].Day)
+	})
+	return files, nil
+}
+
+// lastUsed returns when the timings of each Key of files were last used,
+// as markUsed marks them.
+func lastUsed(files []cacheFile) map[string]time.Time {
+	used := map[string]time.Time{}
+	for _, f := range files {
+		if f.Modified.After(used[f.Key]) {
+			used[f.Key] = f.Modified
+		}
+	}
+	return used
+}
+
+// markUsed marks the timings at path, with info, used today, by their
+// modification time, for KeepLocationDays.
+func markUsed(path string, info os.FileInfo) {
+	if now := time.Now(); now.Sub(info.ModTime()) > 24*time.Hour {
+		os.Chtimes(path, now, now)
+	}
+}
+
+// staleCache returns the files of files cache doesn't keep at now.
+func staleCache(files []cacheFile, now time.Time) []cacheFile {
+	used := lastUsed(files)
+	oldest := time.Date(now.Year(), now.Month()-time.Month(cache.KeepMonths), 1, 0, 0, 0, 0, time.Local)
+	var stale []cacheFile
+	for _, f := range files {
+		if (cache.KeepMonths > 0 && f.Day.Before(oldest)) ||
+			(cache.KeepLocationDays > 0 && now.Sub(used[f.Key]) > time.Duration(cache.KeepLocationDays)*24*time.Hour) {
+			stale = append(stale, f)
+		}
+	}
+	return stale
+}
+
+// CleanCache deletes the timings cache doesn't keep at now, or all of them,
+// and the temporary files left by writes cut short. It returns the number
+// of files deleted and their size.
+func CleanCache(now time.Time, all bool) (int, int64, error) {
+	files, err := cacheFiles()
+	if err != nil {
+		return 0, 0, err
+	}
+	if !all {
+		files = staleCache(files, now)
+	}
+	n, size := 0, int64(0)
+	for _, f := range files {
+		if err := os.Remove(f.Path); err != nil {
+			return n, size, err
+		}
+		n++
+		size += f.Size
+	}
+
+	tmps, _ := filepath.Glob(timingsDir + "timings-*.tmp")
+	for _, path := range tmps {
+		if info, err := os.Stat(path); err == nil && now.Sub(info.ModTime()) > time.Hour {
+			os.Remove(path)
+		}
+	}
+	return n, size, nil
+}
+
+// formatSize formats a size in bytes in KB or MB.
+func formatSize(n int64) string {
+	if n < 1<<20 {
+		return fmt.Sprintf("%d KB", (n+1<<10-1)>>10)
+	}
+	return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+}
+
+const cacheUsage = `Usage: prayer cache [-dir path] command
+  info                           print the timings kept, by place
+  clean [-all]                   delete those not kept by the Cache
+                                 setting, or all of them`
+
+// cacheCommand prints or cleans the timings kept in timingsDir.
+func cacheCommand(args []string) int {
+	fs := flag.NewFlagSet("cache", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprintln(os.Stderr, cacheUsage) }
+	dir := fs.String("dir", "", "directory with the timings")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	if err := openForCommand(*dir); err != nil {
+		fmt.Fprintln(os.Stderr, "cache:", err)
+		return 1
+	}
+
+	now := time.Now()
+	switch fs.Arg(0) {
+	case "info":
+		files, err := cacheFiles()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "cache:", err)
+			return 1
+		}
+		printCache(files, now)
+		return 0
+	case "clean":
+		cfs := flag.NewFlagSet("clean", flag.ExitOnError)
+		all := cfs.Bool("all", false, "delete all the timings, to get them again")
+		cfs.Parse(fs.Args()[1:])
+		n, size, err := CleanCache(now, *all)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "cache:", err)
+			return 1
+		}
+		fmt.Printf("deleted %d files, %s\n", n, formatSize(size))
+		return 0
+	}
+	fmt.Fprintf(os.Stderr, "cache: unknown command %q\n%s\n", fs.Arg(0), cacheUsage)
+	return 2
+}
+
+// printCache prints files by Key, with how much the next clean deletes.
+func printCache(files []cacheFile, now time.Time) {
+	used := lastUsed(files)
+	var total int64
+	for i := 0; i < len(files); {
+		j, size := i, int64(0)
+		for ; j < len(files) && files[j].Key == files[i].Key; j++ {
+			size += files[j].Size
+		}
+		fmt.Printf("%-40s %3d files  %s to %s  last used %s  %s\n", files[i].Key, j-i,
+			files[i].Day.Format("2006-01"), files[j-1].Day.Format("2006-01"),
+			used[files[i].Key].Format(time.DateOnly), formatSize(size))
+		total += size
+		i = j
+	}
+	stale := staleCache(files, now)
+	var staleSize int64
+	for _, f := range stale {
+		staleSize += f.Size
+	}
+	fmt.Printf("%d files in %s, %s; clean deletes %d, %s\n", len(files), timingsDir, formatSize(total),
+		len(stale), formatSize(staleSize))
+}
+
+// writeAtomic writes data to path through a temporary file renamed over it,
+// so that a write cut short, by a crash or a full disk, leaves the old file
+// or none rather than part of the new one.
+func writeAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"ahmed/prayer/praytime"
+)
+
+// Compute the timings here with the angles of method instead of downloading
+// them. The Hijri dates are then those of the tabular calendar, which can be
+// a day apart from the API's.
+var offlineTimings = false
+
+// calcMethods are the API's methods by number.
+var calcMethods = praytime.Methods
+
+// How computed times are rounded to the minute: "nearest", "up" (never
+// early, like many published timetables), "safe" (up, but Imsak and Sunrise
+// down so suhoor and Fajr never end late) or "none", showing the seconds.
+// The API's timings are always to the nearest minute.
+var rounding = "nearest"
+
+// RoundTiming rounds the timing name at t following rounding.
+func RoundTiming(name string, t time.Time) time.Time {
+	switch rounding {
+	case "none":
+		return t.Truncate(time.Second)
+	case "up", "safe":
+		if rounding == "safe" && (name == "Imsak" || name == "Sunrise") {
+			return t.Truncate(time.Minute)
+		}
+		if up := t.Truncate(time.Minute); up.Before(t) {
+			return up.Add(time.Minute)
+		}
+		return t
+	}
+	return t.Round(time.Minute)
+}
+
+// timeLayout is layout, a clock time to the minute, with the seconds when
+// times aren't rounded.
+func timeLayout(layout string) string {
+	if rounding == "none" {
+		return layout + ":05"
+	}
+	return layout
+}
+
+// WriteCalendar writes the month of t at loc to path, in the format of the
+// API's calendar.
+func WriteCalendar(path string, loc Location, t time.Time) error {
+	year, month, _ := t.Date()
+	days := time.Date(year, month+1, 0, 0, 0, 0, 0, time.Local).Day()
+
+	var calendar struct {
+		Data []apiDay `json:"data"`
+	}
+	for d := 1; d <= days; d++ {
+		day := CalcDay(loc, time.Date(year, month, d, 0, 0, 0, 0, time.Local))
+		for _, name := range prayerNames {
+			if _, ok := day.Timings[name]; !ok {
+				return fmt.Errorf("the sun doesn't reach %s on %d-%02d-%02d here: choose another method or download the timings", name, year, month, d)
+			}
+		}
+		calendar.Data = append(calendar.Data, day)
+	}
+
+	data, err := json.Marshal(calendar)
+	if err != nil {
+		return err
+	}
+	return writeTimings(path, data)
+}
+
+// CalcDay computes the timings and Hijri date of day at loc, as the API's
+// entry of the day, without those the sun doesn't reach that day.
+func CalcDay(loc Location, day time.Time) apiDay {
+	y, m, d := day.Date()
+	day = time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	h := praytime.HijriDate(day)
+
+	cm, ok := calcMethods[loc.CalcMethod()]
+	if !ok {
+		cm = calcMethods[3]
+	}
+	coords := praytime.Coordinates{Latitude: loc.Latitude, Longitude: loc.Longitude, Elevation: loc.Elevation}
+	opts := praytime.Options{School: school, Shafaq: shafaq, JafariMidnight: jafariMidnight(loc.CalcMethod())}
+
+	s := praytime.Compute(cm, coords, day, opts)
+	timings := make(map[string]string, len(s.Prayers))
+	for _, p := range s.Prayers {
+		if p.Time.IsZero() {
+			continue
+		}
+		timings[p.Name] = RoundTiming(p.Name, p.Time).Format(timeLayout("15:04") + " (-0700)")
+	}
+
+	return apiDay{
+		Timings: timings,
+		Meta:    apiMeta{Timezone: time.Local.String()},
+		Date: apiDate{Hijri: apiHijri{
+			Day:   json.Number(strconv.Itoa(h.Day)),
+			Year:  json.Number(strconv.Itoa(h.Year)),
+			Month: apiHijriMonth{Number: json.Number(strconv.Itoa(h.Month)), En: h.MonthName()},
+		}},
+	}
+}
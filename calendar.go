@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Play the adhan as a silent notification while a meeting of Calendar is in
+// progress. Calendar is an ICS file, or an http(s) URL to one such as the
+// export link of a CalDAV calendar, read again every Refresh. All-day and
+// free events don't count.
+var calendarMute = struct {
+	Enabled  bool
+	Calendar string
+	Username string // for URLs that need basic auth
+	Password string
+	Refresh  Duration
+}{
+	Enabled: false,
+	Refresh: Duration(15 * time.Minute),
+}
+
+// Meeting is a busy event of the calendar. Repeat, when set, is its RRULE.
+type Meeting struct {
+	Summary    string
+	Start, End time.Time
+	Repeat     map[string]string
+}
+
+var (
+	meetingsMu     sync.Mutex
+	meetings       []Meeting
+	meetingsLoaded time.Time
+	meetingsBusy   bool // a load is running
+)
+
+// calendarClient gets a calendar URL, giving up on a server that hangs so
+// the calendar is loaded again at the next refresh.
+var calendarClient = &http.Client{Timeout: 30 * time.Second}
+
+// CurrentMeeting returns the meeting in progress at t, if any.
+func CurrentMeeting(t time.Time) (Meeting, bool) {
+	meetingsMu.Lock()
+	defer meetingsMu.Unlock()
+
+	for _, m := range meetings {
+		if m.At(t) {
+			return m, true
+		}
+	}
+	return Meeting{}, false
+}
+
+// RefreshMeetings reloads the calendar in the background once it's older
+// than Refresh.
+func RefreshMeetings() {
+	if !calendarMute.Enabled || calendarMute.Calendar == "" {
+		return
+	}
+
+	meetingsMu.Lock()
+	defer meetingsMu.Unlock()
+
+	if meetingsBusy || time.Since(meetingsLoaded) < time.Duration(calendarMute.Refresh) {
+		return
+	}
+	meetingsBusy = true
+	go func() {
+		m, err := LoadMeetings(calendarMute.Calendar)
+		meetingsMu.Lock()
+		defer meetingsMu.Unlock()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "calendar:", err)
+		} else {
+			meetings = m
+		}
+		meetingsLoaded = time.Now()
+		meetingsBusy = false
+	}()
+}
+
+// LoadMeetings reads the busy events of the ICS file or URL calendar.
+func LoadMeetings(calendar string) ([]Meeting, error) {
+	var r io.Reader
+	if strings.HasPrefix(calendar, "http://") || strings.HasPrefix(calendar, "https://") {
+		req, err := http.NewRequest("GET", calendar, nil)
+		if err != nil {
+			return nil, err
+		}
+		if calendarMute.Username != "" {
+			req.SetBasicAuth(calendarMute.Username, calendarMute.Password)
+		}
+		resp, err := calendarClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.New(calendar + ": " + resp.Status)
+		}
+		r = resp.Body
+	} else {
+		f, err := os.Open(calendar)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	return ParseICS(r)
+}
+
+// ParseICS returns the VEVENTs of an iCalendar stream that make one busy.
+func ParseICS(r io.Reader) ([]Meeting, error) {
+	// Unfold the lines continued on the next one after a space or tab.
+	var lines []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	var meetings []Meeting
+	var m Meeting
+	var inEvent, skip, allDay bool
+	var duration time.Duration
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, params, _ := strings.Cut(name, ";")
+
+		switch name {
+		case "BEGIN":
+			if value == "VEVENT" {
+				m, inEvent, skip, allDay, duration = Meeting{}, true, false, false, 0
+			}
+		case "END":
+			if value != "VEVENT" || !inEvent {
+				continue
+			}
+			inEvent = false
+			if m.End.IsZero() {
+				m.End = m.Start.Add(duration)
+			}
+			if !skip && !allDay && m.End.After(m.Start) {
+				meetings = append(meetings, m)
+			}
+		case "SUMMARY":
+			m.Summary = value
+		case "DTSTART", "DTEND":
+			t, date, err := parseICSTime(value, params)
+			if err != nil {
+				return nil, err
+			}
+			allDay = allDay || date
+			if name == "DTSTART" {
+				m.Start = t
+			} else {
+				m.End = t
+			}
+		case "DURATION":
+			d, err := parseICSDuration(value)
+			if err != nil {
+				return nil, err
+			}
+			duration = d
+		case "TRANSP":
+			skip = skip || value == "TRANSPARENT"
+		case "STATUS":
+			skip = skip || value == "CANCELLED"
+		case "RRULE":
+			m.Repeat = make(map[string]string)
+			for _, part := range strings.Split(value, ";") {
+				k, v, _ := strings.Cut(part, "=")
+				m.Repeat[k] = v
+			}
+		}
+	}
+	return meetings, nil
+}
+
+// parseICSTime parses a DATE-TIME in UTC, in the TZID of params or local,
+// or a DATE, reporting which.
+func parseICSTime(value, params string) (time.Time, bool, error) {
+	loc := time.Local
+	for _, p := range strings.Split(params, ";") {
+		if k, v, _ := strings.Cut(p, "="); k == "TZID" {
+			if l, err := time.LoadLocation(strings.Trim(v, `"`)); err == nil {
+				loc = l
+			}
+		}
+	}
+
+	switch {
+	case strings.HasSuffix(value, "Z"):
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	case strings.Contains(value, "T"):
+		t, err := time.ParseInLocation("20060102T150405", value, loc)
+		return t, false, err
+	}
+	t, err := time.ParseInLocation("20060102", value, loc)
+	return t, true, err
+}
+
+// parseICSDuration parses a DURATION like PT1H30M or P1D.
+func parseICSDuration(value string) (time.Duration, error) {
+	units := map[byte]time.Duration{
+		'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour,
+		'H': time.Hour, 'M': time.Minute, 'S': time.Second,
+	}
+	s := strings.TrimPrefix(strings.TrimPrefix(value, "+"), "P")
+	var d time.Duration
+	n := ""
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == 'T':
+		case c >= '0' && c <= '9':
+			n += string(c)
+		default:
+			v, err := strconv.Atoi(n)
+			if err != nil || units[c] == 0 {
+				return 0, errors.New("bad duration " + value)
+			}
+			d += time.Duration(v) * units[c]
+			n = ""
+		}
+	}
+	return d, nil
+}
+
+var icsWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// At reports whether an occurrence of m is in progress at t. Of the
+// repeating rules, only DAILY and WEEKLY ones with INTERVAL, BYDAY, COUNT
+// and UNTIL are understood, the others counting as their first occurrence.
+func (m Meeting) At(t time.Time) bool {
+	length := m.End.Sub(m.Start)
+	if !t.Before(m.Start) && t.Before(m.End) {
+		return true
+	}
+	freq := m.Repeat["FREQ"]
+	if freq != "DAILY" && freq != "WEEKLY" || t.Before(m.Start) {
+		return false
+	}
+
+	interval, _ := strconv.Atoi(m.Repeat["INTERVAL"])
+	if interval < 1 {
+		interval = 1
+	}
+	count, _ := strconv.Atoi(m.Repeat["COUNT"])
+	var until time.Time
+	if v := m.Repeat["UNTIL"]; v != "" {
+		until, _, _ = parseICSTime(v, "")
+	}
+	days := map[time.Weekday]bool{}
+	for _, d := range strings.Split(m.Repeat["BYDAY"], ",") {
+		if wd, ok := icsWeekdays[d]; ok {
+			days[wd] = true
+		}
+	}
+	if len(days) == 0 {
+		days = nil
+	}
+
+	// Walk the days from the first occurrence, in the clock of its start.
+	y, mo, d := m.Start.Date()
+	first := time.Date(y, mo, d, 0, 0, 0, 0, m.Start.Location())
+	firstWeek := first.AddDate(0, 0, -(int(first.Weekday())+6)%7) // weeks start on Monday
+	n := 0
+	for day := first; ; day = day.AddDate(0, 0, 1) {
+		start := time.Date(day.Year(), day.Month(), day.Day(),
+			m.Start.Hour(), m.Start.Minute(), m.Start.Second(), 0, m.Start.Location())
+		if start.After(t) || !until.IsZero() && start.After(until) {
+			return false
+		}
+
+		var match bool
+		if freq == "DAILY" {
+			match = daysBetween(first, day)%interval == 0 && (days == nil || days[day.Weekday()])
+		} else {
+			match = daysBetween(firstWeek, day)/7%interval == 0 && (days == nil && day.Weekday() == first.Weekday() || days[day.Weekday()])
+		}
+		if !match {
+			continue
+		}
+		if n++; count > 0 && n > count {
+			return false
+		}
+		if t.Before(start.Add(length)) {
+			return true
+		}
+	}
+}
+
+// daysBetween returns the calendar days from a to b, both at midnight, be
+// there a DST change between.
+func daysBetween(a, b time.Time) int {
+	return int(math.Round(b.Sub(a).Hours() / 24))
+}
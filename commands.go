@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `Usage:
+  prayer [-minimized] [-dir path]   run the tray app
+  prayer daemon [-dir path]         run the scheduler without a GUI
+  prayer tui [-dir path]            show the timings and countdown in the
+                                    terminal
+  prayer signage [-dir path]        show the timetable fullscreen, for a TV
+                                    at a mosque
+  prayer autostart enable|disable   start the app minimized at login
+  prayer install-service            run the daemon as a systemd user service,
+                                    or a Windows service
+  prayer uninstall-service          remove the service
+  prayer update                     install the latest release
+  prayer config export [file]       back up settings and the prayer log
+  prayer config import file         restore a backup
+  prayer status [-dir path] [-template text]
+                                    print the next prayer, or the schedule
+                                    through a Go template
+  prayer next [-dir path] [-n count]
+                                    print the next prayers, into the next
+                                    day, with their countdowns
+  prayer applet [-dir path]         print the next prayer and timings for
+                                    Argos, Kargos and similar panel applets
+  prayer wait [-dir path] timing     sleep until the next time of a prayer,
+                                    or an offset from it like fajr-30m
+  prayer exec [-dir path] -at timing -- command [args]
+                                    run command at the next time of a
+                                    prayer, or an offset from it like
+                                    maghrib-20m
+  prayer doctor [-dir path]         check the config, network, cached timings,
+                                    audio, sounds and tray, saying what to fix
+  prayer cache [-dir path] info|clean [-all]
+                                    print the timings kept, or delete the
+                                    old ones
+  prayer prefetch [-dir path] [-year year]
+                                    cache the timings of the rest of the
+                                    year, to be used offline
+  prayer remote [-server url] [-token token] command
+                                    manage a server: health, reload,
+                                    restart, announcements, announce`
+
+// runCommand runs the CLI subcommand name and returns the exit code.
+func runCommand(name string, args []string) int {
+	switch name {
+	case "autostart":
+		return autostartCommand(args)
+	case "daemon":
+		return daemonCommand(args)
+	case "tui":
+		return tuiCommand(args)
+	case "signage":
+		return signageCommand(args)
+	case "service":
+		return serviceCommand(args)
+	case "install-service", "uninstall-service":
+		f := InstallService
+		if name == "uninstall-service" {
+			f = UninstallService
+		}
+		if err := f(); err != nil {
+			fmt.Fprintln(os.Stderr, name+":", err)
+			return 1
+		}
+		return 0
+	case "config":
+		return configCommand(args)
+	case "update":
+		return updateCommand(args)
+	case "status":
+		return statusCommand(args)
+	case "next":
+		return nextCommand(args)
+	case "applet":
+		return appletCommand(args)
+	case "wait":
+		return waitCommand(args)
+	case "exec":
+		return execCommand(args)
+	case "doctor":
+		return doctorCommand(args)
+	case "cache":
+		return cacheCommand(args)
+	case "prefetch":
+		return prefetchCommand(args)
+	case "remote":
+		return remoteCommand(args)
+	case "help":
+		fmt.Println(usage)
+		return 0
+	}
+
+	fmt.Fprintln(os.Stderr, usage)
+	return 2
+}
+
+// openForCommand changes to dir, when set, and loads the config and the
+// active profile, for the commands reading the timings.
+func openForCommand(dir string) error {
+	if dir != "" {
+		if err := os.Chdir(dir); err != nil {
+			return err
+		}
+	}
+	if err := LoadConfig(); err != nil {
+		return err
+	}
+	OpenLog()
+	RestoreProfile()
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The AlAdhan API hasn't always written its calendars the same way, nor do
+// its mirrors: the zones after the timings come as "(+03)", "(+0530)",
+// "(+05:30)", "(UTC+3)", "(BST)" or not at all, the numbers of the Hijri
+// date as numbers or strings, and new timings are added from time to time.
+// These are read into the one apiDay the rest of the app sees, and the
+// fields not read, like the method, are left whatever their type.
+
+// splitTiming splits a timing into its clock, on January 1st of year 0,
+// and its zone's offset east of UTC in seconds, ok false when the zone is
+// only abbreviated, like "(BST)", or left out.
+func splitTiming(v string) (clock time.Time, offset int, ok bool, err error) {
+	s, zone, _ := strings.Cut(strings.TrimSpace(v), " ")
+	clock, err = time.Parse("15:04", s)
+	if err != nil {
+		clock, err = time.Parse("15:04:05", s)
+	}
+	if err != nil {
+		return time.Time{}, 0, false, fmt.Errorf("%q is not a time", v)
+	}
+
+	zone = strings.Trim(strings.TrimSpace(zone), "()")
+	for _, prefix := range []string{"UTC", "GMT"} {
+		if strings.HasPrefix(zone, prefix) {
+			zone = strings.TrimPrefix(zone, prefix)
+			if zone == "" {
+				return clock, 0, true, nil
+			}
+		}
+	}
+	if zone == "" || (zone[0] != '+' && zone[0] != '-') {
+		return clock, 0, false, nil
+	}
+	hours, minutes, found := strings.Cut(zone[1:], ":")
+	if !found && len(hours) > 2 {
+		hours, minutes = hours[:len(hours)-2], hours[len(hours)-2:]
+	}
+	h, herr := strconv.Atoi(hours)
+	m := 0
+	if minutes != "" {
+		m, err = strconv.Atoi(minutes)
+	}
+	if herr != nil || err != nil || h > 14 || m > 59 {
+		return time.Time{}, 0, false, fmt.Errorf("%q has no zone like (+03)", v)
+	}
+	offset = h*3600 + m*60
+	if zone[0] == '-' {
+		offset = -offset
+	}
+	return clock, offset, true, nil
+}
+
+// apiNumber reads a number the API writes as a number or a string.
+func apiNumber(n string) (int, error) {
+	i, err := strconv.Atoi(strings.TrimSpace(n))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", n)
+	}
+	return i, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitTiming(t *testing.T) {
+	tests := []struct {
+		v      string
+		clock  string
+		offset int
+		ok     bool
+		err    bool
+	}{
+		{v: "05:12 (+03)", clock: "05:12", offset: 3 * 3600, ok: true},
+		{v: "05:12 (+0530)", clock: "05:12", offset: 5*3600 + 30*60, ok: true},
+		{v: "05:12 (+05:30)", clock: "05:12", offset: 5*3600 + 30*60, ok: true},
+		{v: "05:12 (-0330)", clock: "05:12", offset: -(3*3600 + 30*60), ok: true},
+		{v: "05:12 (UTC+3)", clock: "05:12", offset: 3 * 3600, ok: true},
+		{v: "05:12 (UTC)", clock: "05:12", ok: true},
+		{v: "05:12 (BST)", clock: "05:12"},
+		{v: "05:12", clock: "05:12"},
+		{v: "05:12:30", clock: "05:12"},
+		{v: "05:12 (+3x)", err: true},
+		{v: "05:12 (+15)", err: true},
+		{v: "5 o'clock", err: true},
+	}
+	for _, tt := range tests {
+		clock, offset, ok, err := splitTiming(tt.v)
+		if tt.err {
+			if err == nil {
+				t.Errorf("splitTiming(%q) = %s, want an error", tt.v, clock.Format("15:04"))
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("splitTiming(%q): %v", tt.v, err)
+			continue
+		}
+		if clock.Format("15:04") != tt.clock || offset != tt.offset || ok != tt.ok {
+			t.Errorf("splitTiming(%q) = %s, %d, %v, want %s, %d, %v",
+				tt.v, clock.Format("15:04"), offset, ok, tt.clock, tt.offset, tt.ok)
+		}
+	}
+}
+
+func TestApiNumber(t *testing.T) {
+	for _, n := range []string{"9", " 9"} {
+		if i, err := apiNumber(n); err != nil || i != 9 {
+			t.Errorf("apiNumber(%q) = %d, %v, want 9", n, i, err)
+		}
+	}
+	if _, err := apiNumber("Ramadan"); err == nil {
+		t.Error(`apiNumber("Ramadan") has no error`)
+	}
+}
+
+func TestDecodeMonth(t *testing.T) {
+	day := func(hijri, timings string) string {
+		return `{"code": 200, "data": [{"timings": {"Fajr": "05:12 (+03)", "Dhuhr": "12:01 (+03)",
+			"Asr": "15:20 (+03)", "Maghrib": "18:02 (+03)", "Isha": "19:32 (+03)"` + timings + `},
+			"date": {"hijri": ` + hijri + `}, "meta": {"timezone": "Asia/Riyadh"}}]}`
+	}
+	numbers := `{"day": 1, "month": {"number": 9, "en": "Ramaḍān"}, "year": 1445}`
+	strs := `{"day": "1", "month": {"number": "9", "en": "Ramaḍān"}, "year": "1445"}`
+
+	tests := []struct {
+		name, data string
+		err        string
+		timings    int
+	}{
+		{name: "numbers", data: day(numbers, ""), timings: 5},
+		{name: "strings", data: day(strs, ""), timings: 5},
+		{name: "extra timing", data: day(numbers, `, "Sunrise": "06:20 (+03)"`), timings: 6},
+		{name: "dropped timing", data: day(numbers, `, "Lastthird": "n/a"`), timings: 5},
+		{name: "bad month", data: day(`{"day": 1, "month": {"number": 13}, "year": 1445}`, ""), err: "data[0].date.hijri.month.number"},
+		{name: "bad year", data: day(`{"day": 1, "month": {"number": 9}, "year": "AH"}`, ""), err: `"AH"`},
+		{name: "missing prayer", data: `{"data": [{"timings": {"Fajr": "05:12"}}]}`, err: "missing data[0].timings.Dhuhr"},
+		{name: "api error", data: `{"code": 400, "status": "BAD_REQUEST", "data": "Invalid method"}`, err: "Invalid method"},
+	}
+	for _, tt := range tests {
+		days, err := DecodeMonth([]byte(tt.data), false)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: error %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if n := len(days[0].Timings); n != tt.timings {
+			t.Errorf("%s: %d timings, want %d", tt.name, n, tt.timings)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"time"
+)
+
+var configPath = "./config.json"
+
+// Duration is a time.Duration written as "5m0s" in the config file.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := time.ParseDuration(string(b))
+	*d = Duration(v)
+	return err
+}
+
+// settings maps config file keys to the variables they set.
+func settings() map[string]interface{} {
+	version := configVersion
+	return map[string]interface{}{
+		"Version":             &version,
+		"Location":            &location,
+		"Profiles":            &profiles,
+		"Cities":              &cities,
+		"Method":              &method,
+		"School":              &school,
+		"MidnightMode":        &midnightMode,
+		"Shafaq":              &shafaq,
+		"OfflineTimings":      &offlineTimings,
+		"Rounding":            &rounding,
+		"ExtraTimings":        &extraTimings,
+		"AdhanSound":          &adhanSound,
+		"Notifiers":           &notifiers,
+		"Reminders":           &defaultReminders,
+		"PrayerReminders":     &prayerReminders,
+		"TravelMode":          &travelMode,
+		"OSLocation":          &osLocation,
+		"TravelCheckInterval": (*Duration)(&travelCheckInterval),
+		"TravelDistance":      &travelDistance,
+		"TasbihTargets":       &tasbihTargets,
+		"ShowAdhkar":          &showAdhkar,
+		"AdhkarReminders":     &adhkarReminders,
+		"KahfReminder":        &kahfReminder,
+		"ShowDuaAfterAdhan":   &showDuaAfterAdhan,
+		"DuaTimeout":          (*Duration)(&duaTimeout),
+		"MakruhWarnings":      &makruhWarnings,
+		"MakruhSound":         &makruhSound,
+		"AfterSunrise":        (*Duration)(&afterSunrise),
+		"BeforeDhuhr":         (*Duration)(&beforeDhuhr),
+		"BeforeMaghrib":       (*Duration)(&beforeMaghrib),
+		"TahajjudAlarm":       &tahajjudAlarm,
+		"DuhaReminder":        &duhaReminder,
+		"HijriMonths":         &hijriMonths,
+		"EidPrayer":           &eidPrayer,
+		"Takbeerat":           &takbeerat,
+		"Imsak":               &imsak,
+		"FastingReminders":    &fastingReminders,
+		"CustomReminders":     &customReminders,
+		"WeeklyEmail":         &weeklyEmail,
+		"ShowVerse":           &showVerse,
+		"OnlineVerse":         &onlineVerse,
+		"CalendarMute":        &calendarMute,
+		"WindowAlert":         &windowAlert,
+		"Escalation":          &escalation,
+		"FocusMode":           &focusMode,
+		"ShowSinceAdhan":      &showSinceAdhan,
+		"SNITray":             &sniTray,
+		"UIScale":             &uiScale,
+		"AnnounceNextPrayer":  &announceNextPrayer,
+		"PrayerNameStyle":     &prayerNameStyle,
+		"CountdownFormat":     &countdownFormat,
+		"CountdownInTitle":    &countdownInTitle,
+		"UpcomingPrayers":     &upcomingCount,
+		"TaskbarProgress":     &taskbarProgress,
+		"LockScreenToasts":    &lockScreenToasts,
+		"PrayerLabels":        &prayerLabels,
+		"HistoryLength":       &historyLength,
+		"Snooze":              (*Duration)(&snoozeDuration),
+		"SnoozeMax":           &snoozeMax,
+		"CheckUpdates":        &checkUpdates,
+		"ConfigReload":        &configReload,
+		"Plugins":             &runPlugins,
+		"PluginsDir":          &pluginsDir,
+		"Scripts":             &scripts,
+		"StreamDeck":          &streamDeck,
+		"Server":              &server,
+		"Signage":             &signage,
+		"Iqama":               &iqama,
+		"Theme":               &themePath,
+		"Cache":               &cache,
+		"PrefetchMonths":      &prefetchMonths,
+		"TimeZone":            &timeZone,
+	}
+}
+
+// applySettings sets the variables of the keys in data, leaving the others
+// at their current values. It returns the version data was written with,
+// its settings having been upgraded from that version.
+func applySettings(data []byte) (int, error) {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return 0, err
+	}
+	version, err := migrateSettings(m)
+	if err != nil {
+		return version, err
+	}
+
+	fields := settings()
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v, ok := fields[k]
+		if !ok {
+			return version, fmt.Errorf("unknown setting %q", k)
+		}
+		if err := json.Unmarshal(m[k], v); err != nil {
+			return version, fmt.Errorf("setting %s: %w", k, err)
+		}
+	}
+	return version, nil
+}
+
+// applyValidSettings is applySettings keeping data only if the settings it
+// leads to are valid: otherwise every setting is put back as it was, so the
+// app doesn't go on with, nor save, what was rejected.
+func applyValidSettings(data []byte) (int, error) {
+	fields := settings()
+	saved := make(map[string][]byte, len(fields))
+	for k, v := range fields {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return 0, fmt.Errorf("setting %s: %w", k, err)
+		}
+		saved[k] = b
+	}
+
+	version, err := applySettings(data)
+	if err == nil {
+		err = ValidateSettings()
+	}
+	if err != nil {
+		for k, v := range fields {
+			// Zeroed first, as decoding keeps the keys of maps and the
+			// fields left out.
+			p := reflect.ValueOf(v).Elem()
+			p.Set(reflect.Zero(p.Type()))
+			json.Unmarshal(saved[k], v)
+		}
+	}
+	return version, err
+}
+
+// LoadConfig applies the config file over the defaults, if there is one.
+// A file from an older version is upgraded and saved, the original kept
+// beside it.
+func LoadConfig() error {
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return applyTimeZone()
+	}
+	if err != nil {
+		return err
+	}
+	version, err := applyValidSettings(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", configPath, err)
+	}
+	if err := applyTimeZone(); err != nil {
+		return fmt.Errorf("%s: TimeZone: %w", configPath, err)
+	}
+
+	if version < configVersion {
+		if err := os.WriteFile(fmt.Sprintf("%s.v%d", configPath, version), data, 0644); err != nil {
+			return err
+		}
+		return SaveConfig()
+	}
+	return nil
+}
+
+// SaveConfig writes every setting to the config file.
+func SaveConfig() error {
+	data, err := json.MarshalIndent(settings(), "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(configPath, append(data, '\n'), 0644)
+}
+
+// Backup bundles the settings and the prayer log, to move them to another
+// machine.
+type Backup struct {
+	Settings json.RawMessage
+	Prayers  []LoggedPrayer
+	Qada     map[string]int
+}
+
+func ExportBackup(w io.Writer) error {
+	s, err := json.Marshal(settings())
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(Backup{
+		Settings: s,
+		Prayers:  LoggedPrayers(),
+		Qada:     QadaCounts(),
+	})
+}
+
+// ImportBackup applies and saves the backed up settings, and merges its
+// prayer log into the local one.
+func ImportBackup(r io.Reader) error {
+	var b Backup
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return err
+	}
+
+	if len(b.Settings) > 0 {
+		if _, err := applyValidSettings(b.Settings); err != nil {
+			return err
+		}
+		if err := SaveConfig(); err != nil {
+			return err
+		}
+	}
+
+	ImportPrayers(b.Prayers)
+	for name, n := range b.Qada {
+		SetQada(name, n)
+	}
+	return nil
+}
+
+func configCommand(args []string) int {
+	if len(args) < 1 || len(args) > 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+
+	if err := LoadConfig(); err != nil {
+		fmt.Fprintln(os.Stderr, "config:", err)
+		return 1
+	}
+	OpenLog()
+
+	var err error
+	switch {
+	case args[0] == "export" && len(args) == 1:
+		err = ExportBackup(os.Stdout)
+	case args[0] == "export":
+		var f *os.File
+		if f, err = os.Create(args[1]); err == nil {
+			err = ExportBackup(f)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}
+	case args[0] == "import" && len(args) == 2:
+		var f *os.File
+		if f, err = os.Open(args[1]); err == nil {
+			err = ImportBackup(f)
+			f.Close()
+		}
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "config:", err)
+		return 1
+	}
+	return 0
+}
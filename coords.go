@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"ahmed/prayer/praytime"
+)
+
+// TimingsQuery asks the server for the timings of a place other than the
+// active location, computed like OfflineTimings with the server's School
+// and Shafaq unless it sets them.
+type TimingsQuery struct {
+	Name      string
+	Latitude  float64
+	Longitude float64
+	Elevation float64
+	Method    int
+	School    int
+	Zone      *time.Location
+}
+
+// coordsCacheSize is how many schedules of queried places are kept.
+const coordsCacheSize = 1024
+
+// coordsCache has the schedules computed for queries, which only depend on
+// the query and the day.
+var coordsCache = struct {
+	sync.Mutex
+	days map[string]praytime.Schedule
+}{days: make(map[string]praytime.Schedule)}
+
+// ParseTimingsQuery reads the query of the lat, lon, elevation, method,
+// school, tz and name parameters, with the others of the active location
+// and settings when only lat and lon are set.
+func ParseTimingsQuery(get func(string) string) (TimingsQuery, error) {
+	q := TimingsQuery{Method: method, School: school, Zone: time.Local}
+	floats := []struct {
+		name string
+		v    *float64
+	}{{"lat", &q.Latitude}, {"lon", &q.Longitude}, {"elevation", &q.Elevation}}
+	for _, f := range floats {
+		s := get(f.name)
+		if s == "" && f.name == "elevation" {
+			continue
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return q, fmt.Errorf("%s %q is not a number", f.name, s)
+		}
+		*f.v = v
+	}
+	ints := []struct {
+		name string
+		v    *int
+	}{{"method", &q.Method}, {"school", &q.School}}
+	for _, f := range ints {
+		if s := get(f.name); s != "" {
+			v, err := strconv.Atoi(s)
+			if err != nil {
+				return q, fmt.Errorf("%s %q is not a number", f.name, s)
+			}
+			*f.v = v
+		}
+	}
+	if tz := get("tz"); tz != "" {
+		zone, err := time.LoadLocation(tz)
+		if err != nil {
+			return q, fmt.Errorf("tz %q is not a known time zone", tz)
+		}
+		q.Zone = zone
+	}
+	q.Name = get("name")
+	if q.Name == "" {
+		q.Name = fmt.Sprintf("%g, %g", q.Latitude, q.Longitude)
+	}
+	return q, q.validate()
+}
+
+func (q TimingsQuery) validate() error {
+	if err := ValidateLocation(Location{Name: q.Name, Latitude: q.Latitude, Longitude: q.Longitude}); err != nil {
+		return err
+	}
+	if _, ok := calcMethods[q.Method]; !ok {
+		return fmt.Errorf("method %d is not a known calculation method", q.Method)
+	}
+	if q.School != 0 && q.School != 1 {
+		return fmt.Errorf("school %d is not 0 (Shafi) or 1 (Hanafi)", q.School)
+	}
+	return nil
+}
+
+// CoordsTimings returns the prayers of date, as for parseDate but today
+// being that of the place, at the place of q, without the scheduler or
+// downloads.
+func CoordsTimings(q TimingsQuery, date string) (Timings, error) {
+	day, err := parseDate(date)
+	if err != nil {
+		return Timings{}, err
+	}
+	if date == "" {
+		day = day.In(q.Zone)
+	}
+	y, m, d := day.Date()
+	day = time.Date(y, m, d, 0, 0, 0, 0, q.Zone)
+	key := fmt.Sprint(q.Latitude, q.Longitude, q.Elevation, q.Method, q.School, q.Zone, day.Format("2006-01-02"))
+
+	coordsCache.Lock()
+	sched, ok := coordsCache.days[key]
+	coordsCache.Unlock()
+	if !ok {
+		opts := praytime.Options{School: q.School, Shafaq: shafaq, JafariMidnight: jafariMidnight(q.Method)}
+		coords := praytime.Coordinates{Latitude: q.Latitude, Longitude: q.Longitude, Elevation: q.Elevation}
+		sched = praytime.Compute(calcMethods[q.Method], coords, day, opts)
+
+		coordsCache.Lock()
+		if len(coordsCache.days) >= coordsCacheSize {
+			coordsCache.days = make(map[string]praytime.Schedule)
+		}
+		coordsCache.days[key] = sched
+		coordsCache.Unlock()
+	}
+
+	h := praytime.HijriDate(day)
+	t := Timings{
+		Date:     day.Format("2006-01-02"),
+		Location: q.Name,
+		Hijri:    HijriDate{Day: h.Day, Month: h.Month, Year: h.Year, MonthName: h.MonthName()},
+	}
+	now := time.Now()
+	for _, p := range sched.Prayers {
+		switch p.Name {
+		case "Fajr", "Dhuhr", "Asr", "Maghrib", "Isha":
+			if p.Time.IsZero() {
+				// The sun doesn't reach its angle that day.
+				continue
+			}
+			t.Prayers = append(t.Prayers, statusPrayer(Prayer{Name: p.Name, Time: RoundTiming(p.Name, p.Time)}, now))
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// CustomReminder fires on Days, "Friday" for example or every day when
+// empty, Offset after the After timing ("Maghrib" with "-20m") or at the
+// clock time At ("15:04") when set. The After timing is that of City, one
+// of the Cities, when set, such as Maghrib in Gaza, fired when it comes
+// there whatever the time here.
+type CustomReminder struct {
+	Name    string
+	Days    []string `json:",omitempty"`
+	After   string   `json:",omitempty"`
+	City    string   `json:",omitempty"`
+	Offset  Duration
+	At      string `json:",omitempty"`
+	Message string
+	Sound   string `json:",omitempty"`
+	Text    string `json:",omitempty"`
+}
+
+// Reminders set by the user, e.g.
+//
+//	{Name: "Jumu'ah", Days: []string{"Friday"}, After: "Dhuhr", Offset: Duration(-45 * time.Minute), Message: "Get ready for Jumu'ah"}
+var customReminders = []CustomReminder{}
+
+// On reports whether r fires on day.
+func (r CustomReminder) On(day time.Time) bool {
+	if len(r.Days) == 0 {
+		return true
+	}
+	return contains(r.Days, day.Weekday().String())
+}
+
+func customEvents(day time.Time, timings map[string]time.Time) []Event {
+	var events []Event
+	for _, r := range customReminders {
+		if !r.On(day) {
+			continue
+		}
+		if r.City != "" && r.At == "" {
+			events = append(events, r.cityEvents(day)...)
+			continue
+		}
+		if t, ok := EventTime(day, timings, r.After, time.Duration(r.Offset), r.At); ok {
+			events = append(events, Event{Name: r.Name, Time: t, Message: r.Message, Sound: r.Sound, Text: r.Text})
+		}
+	}
+	return events
+}
+
+// cityEvents returns the events of r, of a timing in its City, coming on
+// day here. The days there can start hours apart from here, so the timing
+// of the day before or after there can be the one coming today here.
+func (r CustomReminder) cityEvents(day time.Time) []Event {
+	city, ok := findCity(r.City)
+	if !ok {
+		return nil
+	}
+	y, m, d := day.Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, day.Location())
+	end := start.AddDate(0, 0, 1)
+
+	var events []Event
+	for _, d := range []time.Time{start.AddDate(0, 0, -1), start, end} {
+		t, ok := DayTimings(city, d)[r.After]
+		if !ok {
+			continue
+		}
+		t = t.Add(time.Duration(r.Offset))
+		if t.Before(start) || !t.Before(end) {
+			continue
+		}
+		msg := r.Message
+		if msg == "" {
+			msg = fmt.Sprintf("%s in %s", r.After, city.Name)
+			// computed offline, the timings are in the zone here
+			if zone := DayZone(DayData(city, d)); zone != nil && zone.String() != time.Local.String() {
+				msg += fmt.Sprintf(", at %s there", t.In(zone).Format(timeLayout("03:04")))
+			}
+		}
+		events = append(events, Event{Name: r.Name, Time: t.In(day.Location()), Message: msg, Sound: r.Sound, Text: r.Text})
+	}
+	return events
+}
+
+// findCity returns the city of Cities named name.
+func findCity(name string) (Location, bool) {
+	for _, c := range cities {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Location{}, false
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// daemonCommand runs the scheduler without a GUI, printing notifications to
+// stdout, for headless machines.
+func daemonCommand(args []string) int {
+	fs := flag.NewFlagSet("daemon", flag.ExitOnError)
+	dir := fs.String("dir", "", "directory with the sounds and timings")
+	fs.Parse(args)
+
+	if *dir != "" {
+		if err := os.Chdir(*dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	if _, ok := SingleInstance(); !ok {
+		fmt.Fprintln(os.Stderr, "Prayer is already running")
+		return 1
+	}
+
+	if err := LoadConfig(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	OpenLog()
+	RestoreProfile()
+
+	sched := NewScheduler()
+	StartIntegrations(sched)
+	sched.Notify = func(title, message string) {
+		fmt.Printf("%s: %s\n", title, message)
+	}
+
+	configChanged := make(chan bool, 1)
+	if configReload {
+		go watchConfig(configChanged)
+	}
+
+	printNext := func(BusEvent) {
+		fmt.Printf("Next prayer is %s at %s\n", sched.Next.Label(), sched.Next.Time.Format("15:04"))
+	}
+	printNext(BusEvent{})
+	sched.Bus.Subscribe(TimingsUpdated, printNext)
+	signals := ShutdownSignals()
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+	for {
+		select {
+		case now := <-tick.C:
+			sched.Tick(now)
+		case <-configChanged:
+			if err := sched.ReloadConfig(); err != nil {
+				fmt.Fprintln(os.Stderr, "config:", err)
+			}
+		case <-signals:
+			sched.Shutdown()
+			return 0
+		}
+	}
+}
@@ -0,0 +1,73 @@
+//go:build !gio
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gen2brain/iup-go/iup"
+)
+
+var citiesDialog iup.Ihandle
+
+// showCitiesDashboard shows the next prayer in every city side by side,
+// like a world clock. The dialog is created on first use and only ticks
+// while visible.
+func showCitiesDashboard() {
+	if citiesDialog != 0 {
+		iup.Show(citiesDialog)
+		return
+	}
+
+	timings := make([]Prayers, len(cities))
+	labels := make([]iup.Ihandle, len(cities))
+	hbox := iup.Hbox()
+
+	update := func() {
+		for i, c := range cities {
+			np, _ := NextPrayer(c, timings[i])
+			iup.SetAttribute(labels[i], "TITLE", fmt.Sprintf("%s at %s\nafter %s",
+				np.Label(), np.Time.Format("03:04"), FormatRemaining(np.Time.Sub(time.Now()))))
+		}
+	}
+
+	now := time.Now()
+	for i, c := range cities {
+		timings[i] = PrayerTimings(c, now)
+
+		labels[i] = iup.Label("")
+		iup.SetAttribute(labels[i], "ALIGNMENT", "ACENTER:ACENTER")
+		iup.SetAttribute(labels[i], "EXPAND", "YES")
+
+		frame := iup.Frame(labels[i])
+		iup.SetAttribute(frame, "TITLE", c.Name)
+		iup.Append(hbox, frame)
+	}
+	update()
+
+	timer := iup.Timer()
+	iup.SetAttribute(timer, "TIME", 1000)
+	iup.SetCallback(timer, "ACTION_CB", iup.TimerActionFunc(func(ih iup.Ihandle) int {
+		update()
+		return iup.DEFAULT
+	}))
+
+	citiesDialog = iup.Dialog(hbox)
+	citiesDialog.SetAttributes(map[string]string{
+		"TITLE":  "Cities",
+		"MARGIN": pxSize(2, 2),
+	})
+
+	iup.SetCallback(citiesDialog, "SHOW_CB", iup.ShowFunc(func(ih iup.Ihandle, state int) int {
+		switch state {
+		case iup.SHOW:
+			iup.SetAttribute(timer, "RUN", "YES")
+		case iup.HIDE, iup.MINIMIZE:
+			iup.SetAttribute(timer, "RUN", "NO")
+		}
+		return iup.DEFAULT
+	}))
+
+	iup.Show(citiesDialog)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// Email the coming week's timetable Offset after Fajr on Day, through the
+// SMTP server at Server ("smtp.example.com:587").
+var weeklyEmail = struct {
+	Enabled  bool
+	Day      string
+	Offset   Duration
+	Server   string
+	Username string
+	Password string
+	From     string
+	To       []string
+}{
+	Day:    "Friday",
+	Offset: Duration(30 * time.Minute),
+}
+
+func weeklyEmailEvents(loc Location, day time.Time, timings map[string]time.Time) []Event {
+	r := weeklyEmail
+	if !r.Enabled || day.Weekday().String() != r.Day {
+		return nil
+	}
+	t, ok := EventTime(day, timings, "Fajr", time.Duration(r.Offset), "")
+	if !ok {
+		return nil
+	}
+	return []Event{{Name: "Weekly email", Time: t, Action: func() {
+		go func() {
+			if err := SendWeeklyEmail(loc, day); err != nil {
+				fmt.Fprintln(os.Stderr, "weekly email:", err)
+			}
+		}()
+	}}}
+}
+
+// FormatWeek is the timetable of the days days from from at loc.
+func FormatWeek(loc Location, from time.Time, days int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-11s", "")
+	for _, name := range prayerNames {
+		fmt.Fprintf(&b, " %-8s", PrayerLabel(name))
+	}
+	b.WriteString("\n")
+	for i := 0; i < days; i++ {
+		day := from.AddDate(0, 0, i)
+		fmt.Fprintf(&b, "%-11s", day.Format("Mon 02 Jan"))
+		for _, p := range PrayerTimings(loc, day) {
+			fmt.Fprintf(&b, " %-8s", p.Time.Format(timeLayout("15:04")))
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// SendWeeklyEmail emails the timetable of the week from day at loc.
+func SendWeeklyEmail(loc Location, day time.Time) error {
+	r := weeklyEmail
+	host, _, err := net.SplitHostPort(r.Server)
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if r.Username != "" {
+		auth = smtp.PlainAuth("", r.Username, r.Password, host)
+	}
+
+	subject := fmt.Sprintf("Prayer times in %s from %s", loc.Name, day.Format("Monday 2 January"))
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s",
+		r.From, strings.Join(r.To, ", "), subject,
+		strings.ReplaceAll(FormatWeek(loc, day, 7), "\n", "\r\n"))
+	return smtp.SendMail(r.Server, auth, r.From, r.To, []byte(msg))
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+	"github.com/faiface/beep/wav"
+)
+
+// doctorCheck is a check of prayer doctor, returning what it found or the
+// problem, written to say how to fix it.
+type doctorCheck struct {
+	Name string
+	Run  func() (string, error)
+}
+
+// doctorCommand checks the setup in dir and prints what to fix, exiting
+// with 1 if anything is wrong.
+func doctorCommand(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	dir := fs.String("dir", "", "directory with the sounds and timings")
+	fs.Parse(args)
+
+	if *dir != "" {
+		if err := os.Chdir(*dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	checks := []doctorCheck{
+		{"Config", checkConfig},
+		{"Timings API", checkAPI},
+		{"Cached timings", checkCachedTimings},
+		{"Audio device", checkAudio},
+		{"Sounds", checkSounds},
+		{"Tray", trayStatus},
+	}
+	failed := 0
+	for _, c := range checks {
+		found, err := c.Run()
+		if err != nil {
+			failed++
+			fmt.Printf("FAIL  %s: %v\n", c.Name, err)
+		} else {
+			fmt.Printf("ok    %s: %s\n", c.Name, found)
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("\n%d of %d checks failed\n", failed, len(checks))
+		return 1
+	}
+	return 0
+}
+
+func checkConfig() (string, error) {
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return "no " + configPath + ", using the defaults", nil
+	}
+	if err := openForCommand(""); err != nil {
+		return "", fmt.Errorf("%w\n      fix the setting, or delete it to use its default", err)
+	}
+	return fmt.Sprintf("%s, at %s", configPath, location.Name), nil
+}
+
+func checkAPI() (string, error) {
+	if offlineTimings {
+		return "not needed with OfflineTimings", nil
+	}
+	client := http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Head(apiUrl)
+	if err != nil {
+		return "", fmt.Errorf("%v\n      check the network or proxy, or set OfflineTimings to compute the timings here", err)
+	}
+	resp.Body.Close()
+	return fmt.Sprintf("%s answered %s", apiUrl, resp.Status), nil
+}
+
+func checkCachedTimings() (string, error) {
+	paths, err := cachePaths()
+	if err != nil {
+		return "", err
+	}
+	var bad []string
+	for _, path := range paths {
+		if err := checkTimingsFile(path); err != nil {
+			bad = append(bad, fmt.Sprintf("%s: %v", path, err))
+		}
+	}
+	if len(bad) > 0 {
+		return "", fmt.Errorf("%s\n      delete these files to have them downloaded again", strings.Join(bad, "\n      "))
+	}
+	return fmt.Sprintf("%d files in %s", len(paths), timingsDir), nil
+}
+
+// checkTimingsFile checks path is a month calendar, as DayData reads it.
+func checkTimingsFile(path string) error {
+	data, err := readTimings(path)
+	if err != nil {
+		return err
+	}
+	days, err := DecodeMonth(data, strings.Contains(path, "-offline-"))
+	if err != nil {
+		return err
+	}
+	if len(days) < 28 {
+		return fmt.Errorf("has %d days, not a month", len(days))
+	}
+	return nil
+}
+
+func checkAudio() (string, error) {
+	sr := beep.SampleRate(44100)
+	if err := speaker.Init(sr, sr.N(time.Second/10)); err != nil {
+		return "", fmt.Errorf("%v\n      check a sound card is connected and not taken by another program", err)
+	}
+	speaker.Close()
+	return "opened", nil
+}
+
+func checkSounds() (string, error) {
+	sounds := soundFiles()
+	var bad []string
+	for _, path := range sounds {
+		if err := checkSound(path); err != nil {
+			bad = append(bad, err.Error())
+		}
+	}
+	if len(bad) > 0 {
+		return "", fmt.Errorf("%s\n      put them here as WAV files, or change the settings naming them",
+			strings.Join(bad, "\n      "))
+	}
+	return strings.Join(sounds, ", "), nil
+}
+
+// checkSound checks path can be played.
+func checkSound(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	streamer, _, err := wav.Decode(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return streamer.Close()
+}
+
+// soundFiles returns the sounds named by the settings, those whose names
+// end in Sound, sorted.
+func soundFiles() []string {
+	found := map[string]bool{}
+	var walk func(name string, v reflect.Value)
+	walk = func(name string, v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Interface:
+			if !v.IsNil() {
+				walk(name, v.Elem())
+			}
+		case reflect.Struct:
+			for i := 0; i < v.NumField(); i++ {
+				if f := v.Type().Field(i); f.IsExported() {
+					walk(f.Name, v.Field(i))
+				}
+			}
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < v.Len(); i++ {
+				walk(name, v.Index(i))
+			}
+		case reflect.Map:
+			for _, k := range v.MapKeys() {
+				walk(name, v.MapIndex(k))
+			}
+		case reflect.String:
+			if strings.HasSuffix(name, "Sound") && v.String() != "" {
+				found[v.String()] = true
+			}
+		}
+	}
+	for name, v := range settings() {
+		walk(name, reflect.ValueOf(v))
+	}
+
+	var sounds []string
+	for s := range found {
+		sounds = append(sounds, s)
+	}
+	sort.Strings(sounds)
+	return sounds
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// DSTChange returns when the clocks change on day, in day's time zone, and
+// by how much.
+func DSTChange(day time.Time) (time.Time, time.Duration, bool) {
+	y, m, d := day.Date()
+	lo := time.Date(y, m, d, 0, 0, 0, 0, day.Location())
+	hi := lo.AddDate(0, 0, 1)
+	_, before := lo.Zone()
+	_, after := hi.Zone()
+	if before == after {
+		return time.Time{}, 0, false
+	}
+
+	for hi.Sub(lo) > time.Second {
+		mid := lo.Add(hi.Sub(lo) / 2)
+		if _, offset := mid.Zone(); offset == before {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi.Round(time.Minute), time.Duration(after-before) * time.Second, true
+}
+
+// FormatDSTChange tells which clock time becomes which at the change at by
+// shift, the clock times of the countdowns jumping then.
+func FormatDSTChange(at time.Time, shift time.Duration) string {
+	_, offset := at.Zone()
+	old := at.In(time.FixedZone("", offset-int(shift.Seconds()))).Format("15:04")
+	way := "forward"
+	if shift < 0 {
+		way = "back"
+	}
+	return fmt.Sprintf("Clocks go %s today, %s becomes %s", way, old, at.Format("15:04"))
+}
@@ -0,0 +1,14 @@
+package main
+
+import "time"
+
+var (
+	showDuaAfterAdhan = true
+	duaTimeout        = 2 * time.Minute
+)
+
+const (
+	duaArabic          = "اللَّهُمَّ رَبَّ هَذِهِ الدَّعْوَةِ التَّامَّةِ، وَالصَّلَاةِ الْقَائِمَةِ، آتِ مُحَمَّدًا الْوَسِيلَةَ وَالْفَضِيلَةَ، وَابْعَثْهُ مَقَامًا مَحْمُودًا الَّذِي وَعَدْتَهُ"
+	duaTransliteration = "Allahumma rabba hadhihi-d-da'wati-t-tammah, wa-s-salati-l-qa'imah, ati Muhammadan al-wasilata wa-l-fadilah, wab'athhu maqaman mahmudan alladhi wa'adtah."
+	duaTranslation     = "O Allah, Lord of this perfect call and established prayer, grant Muhammad the intercession and favour, and raise him to the praised station You have promised him."
+)
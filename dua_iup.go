@@ -0,0 +1,73 @@
+//go:build !gio
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gen2brain/iup-go/iup"
+)
+
+var duaDialog iup.Ihandle
+
+// showDua shows the dua recited after the adhan, hiding it after duaTimeout.
+func showDua() {
+	if duaDialog != 0 {
+		iup.Show(duaDialog)
+		return
+	}
+
+	arabic := iup.Label(duaArabic)
+	arabic.SetAttributes(map[string]string{
+		"FONTSIZE":  scaled(18),
+		"WORDWRAP":  "YES",
+		"ALIGNMENT": "ARIGHT",
+		"EXPAND":    "HORIZONTAL",
+	})
+	transliteration := iup.Label(duaTransliteration)
+	transliteration.SetAttributes(map[string]string{
+		"FONTSTYLE": "Italic",
+		"WORDWRAP":  "YES",
+		"EXPAND":    "HORIZONTAL",
+	})
+	translation := iup.Label(duaTranslation)
+	translation.SetAttributes(map[string]string{
+		"WORDWRAP": "YES",
+		"EXPAND":   "HORIZONTAL",
+	})
+
+	vbox := iup.Vbox(arabic, transliteration, translation)
+	vbox.SetAttributes(map[string]string{
+		"MARGIN": pxSize(8, 8),
+		"GAP":    scaled(8),
+	})
+
+	timer := iup.Timer()
+	iup.SetAttribute(timer, "TIME", int(duaTimeout/time.Millisecond))
+	iup.SetCallback(timer, "ACTION_CB", iup.TimerActionFunc(func(ih iup.Ihandle) int {
+		iup.Hide(duaDialog)
+		return iup.DEFAULT
+	}))
+
+	duaDialog = iup.Dialog(vbox)
+	duaDialog.SetAttributes(map[string]string{
+		"TITLE":      "Dua after adhan",
+		"RASTERSIZE": fmt.Sprintf("%dx", px(500)),
+		"TOPMOST":    "YES",
+	})
+
+	iup.SetCallback(duaDialog, "SHOW_CB", iup.ShowFunc(func(ih iup.Ihandle, state int) int {
+		switch state {
+		case iup.SHOW:
+			// Restart the countdown each time it's shown.
+			iup.SetAttribute(timer, "RUN", "NO")
+			iup.SetAttribute(timer, "RUN", "YES")
+		case iup.HIDE:
+			iup.SetAttribute(timer, "RUN", "NO")
+		}
+		return iup.DEFAULT
+	}))
+
+	iup.Show(duaDialog)
+}
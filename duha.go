@@ -0,0 +1,28 @@
+package main
+
+import "time"
+
+// Reminder for Duha, Offset after sunrise or at the clock time At ("15:04")
+// when set.
+var duhaReminder = struct {
+	Enabled bool
+	Offset  Duration
+	At      string
+	Sound   string
+}{
+	Enabled: false,
+	Offset:  Duration(30 * time.Minute),
+}
+
+func duhaEvents(day time.Time, timings map[string]time.Time) []Event {
+	r := duhaReminder
+	if !r.Enabled {
+		return nil
+	}
+
+	t, ok := EventTime(day, timings, "Sunrise", time.Duration(r.Offset), r.At)
+	if !ok {
+		return nil
+	}
+	return []Event{{Name: "Duha", Time: t, Message: "It's time for Duha prayer", Sound: r.Sound}}
+}
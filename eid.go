@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// The Eid prayer, at Fitr or Adha ("15:04") when set and otherwise Offset
+// after sunrise, at Place. On Eid morning it replaces Dhuhr as the next
+// prayer until it's over and there is no Duha reminder. The reminder
+// Before it plays TakbeeratSound when Takbeerat is on.
+var eidPrayer = struct {
+	Enabled        bool
+	Fitr           string
+	Adha           string
+	Offset         Duration
+	Place          string
+	Before         Duration
+	Takbeerat      bool
+	TakbeeratSound string
+}{
+	Enabled:        true,
+	Offset:         Duration(20 * time.Minute),
+	Before:         Duration(30 * time.Minute),
+	Takbeerat:      false,
+	TakbeeratSound: "takbeerat.wav",
+}
+
+// EidPrayer returns the Eid prayer of day, if it's an Eid.
+func EidPrayer(h HijriDate, day time.Time, timings map[string]time.Time) (Prayer, bool) {
+	name, ok := h.Eid()
+	if !eidPrayer.Enabled || !ok {
+		return Prayer{}, false
+	}
+
+	at := eidPrayer.Fitr
+	if h.Month == DhulHijjah {
+		at = eidPrayer.Adha
+	}
+	t, ok := EventTime(day, timings, "Sunrise", time.Duration(eidPrayer.Offset), at)
+	return Prayer{Name: name, Time: t}, ok
+}
+
+// Upcoming returns the prayer counted down to: Next, or the Eid prayer on
+// Eid morning.
+func (s *Scheduler) Upcoming() Prayer {
+	if eid := s.Eid; !eid.Time.IsZero() && time.Now().Before(eid.Time) && eid.Time.Before(s.Next.Time) {
+		return eid
+	}
+	return s.Next
+}
+
+func eidEvents(loc Location, day time.Time, timings map[string]time.Time) []Event {
+	p, ok := EidPrayer(Hijri(loc, day), day, timings)
+	if !ok {
+		return nil
+	}
+
+	msg := fmt.Sprintf("%s prayer in %v minutes", p.Name, time.Duration(eidPrayer.Before).Minutes())
+	if eidPrayer.Place != "" {
+		msg += " at " + eidPrayer.Place
+	}
+	sound := ""
+	if eidPrayer.Takbeerat {
+		sound = eidPrayer.TakbeeratSound
+	}
+	return []Event{{
+		Name:    p.Name,
+		Time:    p.Time.Add(-time.Duration(eidPrayer.Before)),
+		Message: msg,
+		Sound:   sound,
+	}}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Replay a short alert every After following the adhan, Repeats times and
+// louder each time, until the prayer is marked as prayed or dismissed.
+var escalation = struct {
+	Enabled bool
+	After   Duration
+	Repeats int
+	Sound   string
+}{
+	Enabled: false,
+	After:   Duration(10 * time.Minute),
+	Repeats: 3,
+	Sound:   "tasbih.wav",
+}
+
+// escalate fires the next escalation of the current prayer if it's due.
+func (s *Scheduler) escalate(now time.Time) {
+	if !escalation.Enabled || s.dismissed || s.escalations >= escalation.Repeats {
+		return
+	}
+
+	due := s.Current.Time.Add(time.Duration(escalation.After) * time.Duration(s.escalations+1))
+	if due.Sub(now).Round(time.Second) != 0 || !due.Before(s.CurrentEnd) {
+		return
+	}
+	s.escalations++
+	if IsPrayed(s.Current) {
+		return
+	}
+
+	// The last repeat plays at full volume, each one before at half the next.
+	volume := -float64(escalation.Repeats - s.escalations)
+	msg := fmt.Sprintf("%s was %v ago", s.Current.Label(), now.Sub(s.Current.Time).Round(time.Minute))
+	s.alertVolume("Escalation", s.Current.Name, msg, escalation.Sound, volume)
+}
+
+// Dismiss stops the escalation of the current prayer.
+func (s *Scheduler) Dismiss() {
+	s.dismissed = true
+}
@@ -0,0 +1,54 @@
+package main
+
+import "time"
+
+// Event is a reminder at a point of the day other than the prayers
+// themselves. Message is the notification text, Sound a wav file to play at
+// Volume and Text, when not empty, is shown in a window.
+type Event struct {
+	Name    string
+	Time    time.Time
+	Message string
+	Sound   string
+	Volume  float64
+	Text    string
+	Action  func() // run instead of alerting, when set
+}
+
+// DayEvents returns the events scheduled on day at loc.
+func DayEvents(loc Location, day time.Time) []Event {
+	timings := DayTimings(loc, day)
+
+	var events []Event
+	events = append(events, adhkarEvents(timings)...)
+	events = append(events, kahfEvents(day, timings)...)
+	events = append(events, makruhEvents(timings)...)
+	events = append(events, tahajjudEvents(loc, day, timings)...)
+	if _, ok := EidPrayer(Hijri(loc, day), day, timings); !ok {
+		events = append(events, duhaEvents(day, timings)...)
+	}
+	events = append(events, fastingEvents(loc, day, timings)...)
+	events = append(events, imsakEvents(loc, day, timings)...)
+	events = append(events, hijriEvents(loc, day, timings)...)
+	events = append(events, eidEvents(loc, day, timings)...)
+	events = append(events, takbeeratEvents(loc, day, timings)...)
+	events = append(events, customEvents(day, timings)...)
+	events = append(events, weeklyEmailEvents(loc, day, timings)...)
+	return events
+}
+
+// EventTime returns offset after the after timing, or the clock time at
+// ("15:04") on day when it's set.
+func EventTime(day time.Time, timings map[string]time.Time, after string, offset time.Duration, at string) (time.Time, bool) {
+	if at != "" {
+		clock, err := time.ParseInLocation("15:04", at, day.Location())
+		if err != nil {
+			panic(err)
+		}
+		y, m, d := day.Date()
+		return time.Date(y, m, d, clock.Hour(), clock.Minute(), 0, 0, day.Location()), true
+	}
+
+	t, ok := timings[after]
+	return t.Add(offset), ok
+}
@@ -0,0 +1,28 @@
+//go:build !gio
+
+package main
+
+import "github.com/gen2brain/iup-go/iup"
+
+// showText shows text in a read-only window.
+func showText(title, text string) {
+	t := iup.Text()
+	t.SetAttributes(map[string]string{
+		"MULTILINE":      "YES",
+		"READONLY":       "YES",
+		"WORDWRAP":       "YES",
+		"SCROLLBAR":      "VERTICAL",
+		"EXPAND":         "YES",
+		"VISIBLELINES":   "20",
+		"VISIBLECOLUMNS": "50",
+		"VALUE":          text,
+	})
+
+	dlg := iup.Dialog(t)
+	iup.SetAttribute(dlg, "TITLE", title)
+	iup.SetCallback(dlg, "CLOSE_CB", iup.CloseFunc(func(ih iup.Ihandle) int {
+		iup.Hide(ih)
+		return iup.IGNORE
+	}))
+	iup.Show(dlg)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ParseTimingSpec splits a timing with an optional offset, "maghrib" or
+// "maghrib-20m" for example.
+func ParseTimingSpec(spec string) (name string, offset time.Duration, err error) {
+	name = spec
+	if i := strings.IndexAny(spec, "+-"); i >= 0 {
+		name = spec[:i]
+		if offset, err = time.ParseDuration(spec[i:]); err != nil {
+			return "", 0, fmt.Errorf("bad offset in %q: %w", spec, err)
+		}
+	}
+	if name == "" {
+		return "", 0, fmt.Errorf("no timing in %q", spec)
+	}
+	return name, offset, nil
+}
+
+// NextTiming returns when the timing called name, in any case, is next
+// offset away after now at loc.
+func NextTiming(loc Location, name string, offset time.Duration, now time.Time) (time.Time, error) {
+	for _, day := range []time.Time{now, now.AddDate(0, 0, 1)} {
+		timings := DayTimings(loc, day)
+		var names []string
+		found := false
+		for k, t := range timings {
+			names = append(names, k)
+			if !strings.EqualFold(k, name) {
+				continue
+			}
+			found = true
+			if at := t.Add(offset); at.After(now) {
+				return at, nil
+			}
+		}
+		if !found {
+			sort.Strings(names)
+			return time.Time{}, fmt.Errorf("no timing %q, it's one of %s", name, strings.Join(names, ", "))
+		}
+	}
+	return time.Time{}, errors.New("no such time today or tomorrow")
+}
+
+// sleepUntil sleeps until the clock shows at, even across a suspend.
+func sleepUntil(at time.Time) {
+	at = at.Round(0)
+	for d := time.Until(at); d > 0; d = time.Until(at) {
+		if d > time.Minute {
+			d = time.Minute
+		}
+		time.Sleep(d)
+	}
+}
+
+// waitSetup reads the config and finds when spec is next.
+func waitSetup(dir, spec string) (time.Time, error) {
+	if err := openForCommand(dir); err != nil {
+		return time.Time{}, err
+	}
+
+	name, offset, err := ParseTimingSpec(spec)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return NextTiming(location, name, offset, time.Now())
+}
+
+// waitCommand sleeps until the next time of a prayer, for shell scripts.
+func waitCommand(args []string) int {
+	fs := flag.NewFlagSet("wait", flag.ExitOnError)
+	dir := fs.String("dir", "", "directory with the timings")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+
+	at, err := waitSetup(*dir, fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "wait:", err)
+		return 1
+	}
+	sleepUntil(at)
+	return 0
+}
+
+// execCommand runs a command at the next time of a prayer, returning its
+// exit code.
+func execCommand(args []string) int {
+	fs := flag.NewFlagSet("exec", flag.ExitOnError)
+	dir := fs.String("dir", "", "directory with the timings")
+	spec := fs.String("at", "", "prayer or other timing, with an optional offset like maghrib+10m")
+	fs.Parse(args)
+	if *spec == "" || fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+
+	at, err := waitSetup(*dir, *spec)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "exec:", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Running %s at %s\n", fs.Arg(0), at.Format("2006-01-02 15:04"))
+	sleepUntil(at)
+
+	cmd := exec.Command(fs.Arg(0), fs.Args()[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			return exit.ExitCode()
+		}
+		fmt.Fprintln(os.Stderr, "exec:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,74 @@
+package main
+
+import "time"
+
+// Reminders the evening before sunnah fasts on Mondays and Thursdays and on
+// the white days (13th to 15th of the Hijri month), Offset after the After
+// timing, with an optional suhoor alarm SuhoorBefore Fajr of the fast.
+var fastingReminders = struct {
+	MondayThursday bool
+	WhiteDays      bool
+	After          string
+	Offset         Duration
+	Suhoor         bool
+	SuhoorBefore   Duration
+	Sound          string
+}{
+	MondayThursday: false,
+	WhiteDays:      false,
+	After:          "Isha",
+	Offset:         Duration(30 * time.Minute),
+	SuhoorBefore:   Duration(45 * time.Minute),
+	Sound:          "tasbih.wav",
+}
+
+// FastingDay reports whether day is a fast enabled in fastingReminders or
+// hijriMonths, and which.
+func FastingDay(loc Location, day time.Time) (string, bool) {
+	r := fastingReminders
+	h := Hijri(loc, day)
+	if h.NoFasting() {
+		return "", false
+	}
+	if reason, ok := hijriFast(h); ok {
+		return reason, true
+	}
+	if r.WhiteDays && h.Day >= 13 && h.Day <= 15 {
+		return "white day", true
+	}
+	if r.MondayThursday {
+		switch day.Weekday() {
+		case time.Monday, time.Thursday:
+			return day.Weekday().String(), true
+		}
+	}
+	return "", false
+}
+
+func fastingEvents(loc Location, day time.Time, timings map[string]time.Time) []Event {
+	r := fastingReminders
+	var events []Event
+
+	// Ramadan is announced the evening before its first day only.
+	reason, ok := FastingDay(loc, day.AddDate(0, 0, 1))
+	if ok && (reason != "Ramadan" || !Hijri(loc, day).Ramadan()) {
+		if t, ok := EventTime(day, timings, r.After, time.Duration(r.Offset), ""); ok {
+			events = append(events, Event{
+				Name:    "Fasting tomorrow",
+				Time:    t,
+				Message: "Tomorrow is a fasting day (" + reason + ")",
+				Sound:   r.Sound,
+			})
+		}
+	}
+
+	if reason, ok := FastingDay(loc, day); ok && (r.Suhoor || reason == "Ramadan") {
+		events = append(events, Event{
+			Name:    "Suhoor",
+			Time:    timings["Fajr"].Add(-time.Duration(r.SuhoorBefore)),
+			Message: "Time for suhoor",
+			Sound:   r.Sound,
+		})
+	}
+	return events
+}
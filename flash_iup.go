@@ -0,0 +1,31 @@
+//go:build !gio
+
+package main
+
+import "github.com/gen2brain/iup-go/iup"
+
+// flashDialog shows dlg and blinks its background a few times, to catch
+// the eye when an alert's sound couldn't be played.
+func flashDialog(dlg iup.Ihandle) {
+	iup.Show(dlg)
+	bg := iup.GetAttribute(dlg, "BGCOLOR")
+	blinks := 0
+	timer := iup.Timer()
+	iup.SetAttribute(timer, "TIME", 300)
+	iup.SetCallback(timer, "ACTION_CB", iup.TimerActionFunc(func(ih iup.Ihandle) int {
+		blinks++
+		if blinks > 8 {
+			iup.SetAttribute(dlg, "BGCOLOR", bg)
+			iup.SetAttribute(ih, "RUN", "NO")
+			ih.Destroy()
+			return iup.DEFAULT
+		}
+		if blinks%2 == 1 {
+			iup.SetAttribute(dlg, "BGCOLOR", "255 200 0")
+		} else {
+			iup.SetAttribute(dlg, "BGCOLOR", bg)
+		}
+		return iup.DEFAULT
+	}))
+	iup.SetAttribute(timer, "RUN", "YES")
+}
@@ -0,0 +1,15 @@
+package main
+
+import "time"
+
+// Cover the screen for Duration after the adhan, to step away from the
+// computer. Ctrl+Shift+B lifts it early.
+var focusMode = struct {
+	Enabled  bool
+	Duration Duration
+	Opacity  int // of the overlay, 0 to 255
+}{
+	Enabled:  false,
+	Duration: Duration(10 * time.Minute),
+	Opacity:  230,
+}
@@ -0,0 +1,82 @@
+//go:build !gio
+
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gen2brain/iup-go/iup"
+)
+
+// showFocus shows an overlay that can't be closed over the whole screen
+// until focusMode.Duration is over, or Ctrl+Shift+B is pressed.
+func showFocus(p Prayer) {
+	end := time.Now().Add(time.Duration(focusMode.Duration))
+
+	title := iup.Label("Time for " + p.Label())
+	title.SetAttributes(map[string]string{
+		"FONTSIZE":  scaled(40),
+		"ALIGNMENT": "ACENTER",
+		"EXPAND":    "HORIZONTAL",
+	})
+	countdown := iup.Label("")
+	countdown.SetAttributes(map[string]string{
+		"FONTSIZE":  scaled(20),
+		"ALIGNMENT": "ACENTER",
+		"EXPAND":    "HORIZONTAL",
+	})
+	hint := iup.Label("Ctrl+Shift+B to return early")
+	iup.SetAttribute(hint, "ALIGNMENT", "ACENTER")
+	iup.SetAttribute(hint, "EXPAND", "HORIZONTAL")
+
+	vbox := iup.Vbox(iup.Fill(), title, countdown, iup.Fill(), hint)
+	vbox.SetAttributes(map[string]string{
+		"ALIGNMENT": "ACENTER",
+		"MARGIN":    pxSize(20, 20),
+		"GAP":       scaled(10),
+	})
+
+	dlg := iup.Dialog(vbox)
+	dlg.SetAttributes(map[string]string{
+		"FULLSCREEN": "YES",
+		"TOPMOST":    "YES",
+		"MENUBOX":    "NO",
+		"BGCOLOR":    "0 0 0",
+		"FGCOLOR":    "255 255 255",
+		"OPACITY":    strconv.Itoa(focusMode.Opacity),
+	})
+
+	timer := iup.Timer()
+	iup.SetAttribute(timer, "TIME", 1000)
+	lift := func() {
+		iup.SetAttribute(timer, "RUN", "NO")
+		timer.Destroy()
+		dlg.Destroy()
+	}
+	iup.SetCallback(timer, "ACTION_CB", iup.TimerActionFunc(func(ih iup.Ihandle) int {
+		rem := time.Until(end).Round(time.Second)
+		if rem <= 0 {
+			lift()
+			return iup.DEFAULT
+		}
+		iup.SetAttribute(countdown, "TITLE", FormatRemaining(rem))
+		return iup.DEFAULT
+	}))
+
+	iup.SetCallback(dlg, "CLOSE_CB", iup.CloseFunc(func(ih iup.Ihandle) int {
+		return iup.IGNORE
+	}))
+	iup.SetCallback(dlg, "K_ANY", iup.KAnyFunc(func(ih iup.Ihandle, c int) int {
+		if c == iup.XKeyCtrl(iup.K_B) && strings.Contains(iup.GetGlobal("MODKEYSTATE"), "S") {
+			lift()
+		}
+		// Swallow the other keys, so they don't reach the main window.
+		return iup.IGNORE
+	}))
+
+	iup.SetAttribute(countdown, "TITLE", FormatRemaining(time.Until(end).Round(time.Second)))
+	iup.SetAttribute(timer, "RUN", "YES")
+	iup.Show(dlg)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"ahmed/prayer/prayerpb"
+)
+
+// prayerServer is the gRPC API of the server, the same as its JSON one.
+type prayerServer struct {
+	prayerpb.UnimplementedPrayerServer
+	s *Scheduler
+}
+
+func (p *prayerServer) GetStatus(ctx context.Context, req *prayerpb.GetStatusRequest) (*prayerpb.Status, error) {
+	st, err := ServerStatus(p.s)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	m := &prayerpb.Status{
+		Now:      timestamppb.New(st.Now),
+		Location: st.Location,
+		Next:     pbPrayer(st.Next),
+		Current:  pbPrayer(st.Current),
+		Hijri:    pbHijri(st.Hijri),
+		Makruh:   st.Makruh,
+	}
+	for _, sp := range st.Prayers {
+		m.Prayers = append(m.Prayers, pbPrayer(sp))
+	}
+	return m, nil
+}
+
+func (p *prayerServer) GetTimings(ctx context.Context, req *prayerpb.GetTimingsRequest) (*prayerpb.Timings, error) {
+	var t Timings
+	if req.Latitude != nil || req.Longitude != nil {
+		q, err := ParseTimingsQuery(timingsQueryParams(req))
+		if err == nil {
+			t, err = CoordsTimings(q, req.Date)
+		}
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	} else {
+		day, err := parseDate(req.Date)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if t, err = ServerTimings(p.s, day); err != nil {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+	}
+	m := &prayerpb.Timings{Date: t.Date, Location: t.Location, Hijri: pbHijri(t.Hijri)}
+	for _, sp := range t.Prayers {
+		m.Prayers = append(m.Prayers, pbPrayer(sp))
+	}
+	return m, nil
+}
+
+func (p *prayerServer) Subscribe(req *prayerpb.SubscribeRequest, stream prayerpb.Prayer_SubscribeServer) error {
+	events, unsubscribe := SubscribeEvents(p.s, req.Topics)
+	defer unsubscribe()
+	for {
+		select {
+		case m := <-events:
+			ev := &prayerpb.Event{
+				Topic: m.Topic,
+				Time:  timestamppb.New(m.Time),
+				Text:  m.Text,
+				Next:  &prayerpb.PrayerTime{Name: m.Next.Name, Label: m.Next.Label(), Time: timestamppb.New(m.Next.Time)},
+			}
+			if a := m.Alert; a != nil {
+				ev.Alert = &prayerpb.Alert{Time: timestamppb.New(a.Time), Kind: a.Kind, Name: a.Name, Message: a.Message, Sound: a.Sound}
+			}
+			if l := m.Location; l != nil {
+				ev.Location = &prayerpb.Location{Name: l.Name, Latitude: l.Latitude, Longitude: l.Longitude}
+			}
+			if err := stream.Send(ev); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// timingsQueryParams returns the fields of req as the parameters of
+// /api/timings.
+func timingsQueryParams(req *prayerpb.GetTimingsRequest) func(string) string {
+	params := map[string]string{"tz": req.TimeZone, "name": req.Name}
+	if req.Latitude != nil {
+		params["lat"] = strconv.FormatFloat(*req.Latitude, 'g', -1, 64)
+	}
+	if req.Longitude != nil {
+		params["lon"] = strconv.FormatFloat(*req.Longitude, 'g', -1, 64)
+	}
+	if req.Elevation != 0 {
+		params["elevation"] = strconv.FormatFloat(req.Elevation, 'g', -1, 64)
+	}
+	if req.Method != nil {
+		params["method"] = strconv.Itoa(int(*req.Method))
+	}
+	if req.School != nil {
+		params["school"] = strconv.Itoa(int(*req.School))
+	}
+	return func(name string) string { return params[name] }
+}
+
+func pbPrayer(sp StatusPrayer) *prayerpb.PrayerTime {
+	return &prayerpb.PrayerTime{Name: sp.Name, Label: sp.Label, Time: timestamppb.New(sp.Time), At: sp.At, In: sp.In}
+}
+
+func pbHijri(h HijriDate) *prayerpb.HijriDate {
+	return &prayerpb.HijriDate{Day: int32(h.Day), Month: int32(h.Month), Year: int32(h.Year), MonthName: h.MonthName}
+}
@@ -0,0 +1,241 @@
+//go:build gio
+
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"sync"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/notify"
+)
+
+// guiMain runs the Gio frontend, built with -tags gio instead of IUP. It has
+// the timetable, countdown and notifications, but not yet the tray, Qibla,
+// stats, setup wizard and other windows of the IUP one.
+func guiMain(sched *Scheduler, raise <-chan bool) int {
+	// mu guards sched and the window state between the window and the
+	// ticker goroutine.
+	var mu sync.Mutex
+
+	w := app.NewWindow(
+		app.Title(FormatTitle(sched.Upcoming(), location)),
+		app.Size(unit.Dp(520), unit.Dp(480)),
+	)
+	if startMinimized {
+		w.Perform(system.ActionMinimize)
+	}
+
+	var duaUntil time.Time
+	sched.Notify = func(title, message string) {
+		notify.Push(title, message)
+	}
+	sched.ShowText = func(title, text string) {
+		go textWindow(title, text)
+	}
+	sched.AdhanDone = func() {
+		if showDuaAfterAdhan {
+			mu.Lock()
+			duaUntil = time.Now().Add(duaTimeout)
+			mu.Unlock()
+			w.Invalidate()
+		}
+	}
+
+	configChanged := make(chan bool, 1)
+	if configReload {
+		go watchConfig(configChanged)
+	}
+
+	verse := DailyVerse(time.Now())
+	go func() {
+		lastTitle := ""
+		for now := range time.Tick(time.Second) {
+			mu.Lock()
+			select {
+			case <-configChanged:
+				if err := sched.ReloadConfig(); err != nil {
+					sched.Notify("Config not applied", err.Error())
+				}
+			default:
+			}
+			sched.Tick(now)
+			verse = DailyVerse(now)
+			title := FormatTitle(sched.Upcoming(), location)
+			mu.Unlock()
+			if title != lastTitle {
+				w.Option(app.Title(title))
+				lastTitle = title
+			}
+			w.Invalidate()
+		}
+	}()
+	go func() {
+		for range raise {
+			w.Perform(system.ActionRaise)
+		}
+	}()
+	signals := ShutdownSignals()
+	go func() {
+		<-signals
+		w.Perform(system.ActionClose)
+	}()
+
+	go func() {
+		th := material.NewTheme()
+		th.Face = "monospace"
+
+		rows := make([]widget.Clickable, len(sched.Prayers))
+		var prayedButton, closeButton widget.Clickable
+
+		var ops op.Ops
+		for {
+			switch e := w.NextEvent().(type) {
+			case app.DestroyEvent:
+				mu.Lock()
+				sched.Shutdown()
+				if e.Err != nil {
+					fmt.Fprintln(os.Stderr, e.Err)
+					os.Exit(1)
+				}
+				os.Exit(0)
+			case app.FrameEvent:
+				gtx := app.NewContext(&ops, e)
+				mu.Lock()
+
+				// Click a row to mark a prayer made up late.
+				for i := range rows {
+					if p := sched.Prayers[i]; rows[i].Clicked(gtx) && time.Now().After(p.Time) {
+						MarkPrayed(location, p)
+					}
+				}
+				if prayedButton.Clicked(gtx) {
+					MarkPrayed(location, sched.Current)
+				}
+				if closeButton.Clicked(gtx) {
+					w.Perform(system.ActionClose)
+				}
+
+				layoutMain(gtx, th, sched, rows, &prayedButton, &closeButton, verse, time.Now().Before(duaUntil))
+				mu.Unlock()
+				e.Frame(gtx.Ops)
+			}
+		}
+	}()
+
+	app.Main()
+	return 0
+}
+
+func layoutMain(gtx layout.Context, th *material.Theme, sched *Scheduler, rows []widget.Clickable,
+	prayedButton, closeButton *widget.Clickable, verse Verse, showDua bool) layout.Dimensions {
+	now := time.Now()
+
+	var children []layout.FlexChild
+	label := func(l material.LabelStyle) {
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.UniformInset(unit.Dp(2)).Layout(gtx, l.Layout)
+		}))
+	}
+
+	if !sched.Imsak.Time.IsZero() {
+		label(material.Body1(th, fmt.Sprint(sched.Imsak)))
+	}
+	for i, p := range sched.Prayers {
+		i, row := i, fmt.Sprint(p)
+		if IsPrayed(p) {
+			row += " ✓"
+		}
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return material.Clickable(gtx, &rows[i], material.Body1(th, row).Layout)
+		}))
+	}
+
+	for _, p := range sched.Extra {
+		label(material.Body1(th, fmt.Sprint(p)))
+	}
+
+	label(material.H6(th, FormatNextPrayer(sched.Upcoming())))
+	if s := FormatTomorrowFajr(sched.Next, now); s != "" {
+		label(material.H6(th, s))
+	}
+	if more := UpcomingPrayers(now, upcomingCount, sched.Prayers, sched.Tomorrow); len(more) > 1 {
+		label(material.Body1(th, "then\n"+FormatUpcoming(more[1:])))
+	}
+	if rem := sched.CurrentEnd.Sub(now).Round(time.Second); rem > 0 {
+		label(material.Body1(th, FormatWindow(sched.Current, rem)))
+	}
+	if showSinceAdhan {
+		label(material.Body1(th, FormatSince(sched.Current, now)))
+	}
+	if s := FormatImsak(sched.Imsak); s != "" {
+		label(material.Body1(th, s))
+	}
+	if sched.DSTNotice != "" {
+		label(material.Body1(th, sched.DSTNotice))
+	}
+	if p, ok := sched.MakruhAt(now); ok {
+		l := material.Body1(th, FormatMakruh(p))
+		l.Color = color.NRGBA{R: 200, A: 255}
+		label(l)
+	}
+
+	if showDua {
+		for _, s := range []string{duaArabic, duaTransliteration, duaTranslation} {
+			l := material.Body2(th, s)
+			l.Alignment = text.Middle
+			label(l)
+		}
+	} else if showVerse {
+		l := material.Body2(th, verse.String())
+		l.Alignment = text.Middle
+		label(l)
+	}
+
+	children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{}.Layout(gtx,
+			layout.Rigid(material.Button(th, closeButton, "Close").Layout),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(4)}.Layout),
+			layout.Rigid(material.Button(th, prayedButton, "Prayed").Layout),
+		)
+	}))
+
+	return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+	})
+}
+
+// setupWizard does nothing yet with Gio, which starts with the defaults
+// until a config file is written.
+func setupWizard() {}
+
+// textWindow shows text in a scrollable window.
+func textWindow(title, text string) {
+	w := app.NewWindow(app.Title(title), app.Size(unit.Dp(480), unit.Dp(520)))
+	th := material.NewTheme()
+	list := widget.List{List: layout.List{Axis: layout.Vertical}}
+
+	var ops op.Ops
+	for {
+		switch e := w.NextEvent().(type) {
+		case app.DestroyEvent:
+			return
+		case app.FrameEvent:
+			gtx := app.NewContext(&ops, e)
+			material.List(th, &list).Layout(gtx, 1, func(gtx layout.Context, _ int) layout.Dimensions {
+				return layout.UniformInset(unit.Dp(8)).Layout(gtx, material.Body1(th, text).Layout)
+			})
+			e.Frame(gtx.Ops)
+		}
+	}
+}
@@ -0,0 +1,639 @@
+//go:build !gio
+
+package main
+
+import (
+	"fmt"
+	"image/png"
+	"os"
+	"runtime/cgo"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gen2brain/iup-go/iup"
+)
+
+func guiMain(sched *Scheduler, raise <-chan bool) int {
+	iup.Open()
+	defer iup.Close()
+
+	initScale()
+	iup.SetGlobal("DEFAULTFONT", "Courier "+scaled(15))
+
+	list := iup.List()
+	updateTimings := func() {
+		for i, p := range sched.Prayers {
+			row := fmt.Sprint(p)
+			if IsPrayed(p) {
+				row += " ✓"
+			}
+			iup.SetAttribute(list, fmt.Sprint(i+1), row)
+		}
+	}
+	updateTimings()
+	sched.Bus.Subscribe(TimingsUpdated, func(BusEvent) { updateTimings() })
+
+	markPrayed := func(p Prayer) {
+		MarkPrayed(location, p)
+		updateTimings()
+	}
+
+	// Double click a row, or press Enter on it, to mark a prayer made up late.
+	iup.SetAttribute(list, "TIP", "Prayer times. Double click or press Enter on a past prayer to mark it as prayed.")
+	iup.SetCallback(list, "DBLCLICK_CB", iup.DblclickFunc(func(ih iup.Ihandle, item int, text string) int {
+		if p := sched.Prayers[item-1]; time.Now().After(p.Time) {
+			markPrayed(p)
+		}
+		return iup.DEFAULT
+	}))
+	iup.SetCallback(list, "K_ANY", iup.KAnyFunc(func(ih iup.Ihandle, c int) int {
+		item := iup.GetInt(ih, "VALUE")
+		if c != iup.K_CR || item == 0 {
+			return iup.CONTINUE
+		}
+		if p := sched.Prayers[item-1]; time.Now().After(p.Time) {
+			markPrayed(p)
+		}
+		return iup.DEFAULT
+	}))
+
+	// Imsak has a row of its own above the prayers, in Ramadan.
+	imsakRow := iup.Label("")
+	iup.SetAttribute(imsakRow, "EXPAND", "HORIZONTAL")
+	extraRows := iup.Label("")
+	iup.SetAttribute(extraRows, "EXPAND", "HORIZONTAL")
+	listFrame := iup.Frame(iup.Vbox(imsakRow, list, extraRows))
+	iup.SetAttribute(listFrame, "TITLE", TimingsTitle(sched.Prayers, time.Now()))
+
+	nextPrayer := iup.Label(FormatNextPrayer(sched.Upcoming()))
+
+	iup.SetAttribute(nextPrayer, "ALIGNMENT", "ACENTER:ACENTER")
+	iup.SetAttribute(nextPrayer, "EXPAND", "YES")
+	windowLabel := iup.Label("")
+	iup.SetAttribute(windowLabel, "ALIGNMENT", "ACENTER:ACENTER")
+	iup.SetAttribute(windowLabel, "EXPAND", "HORIZONTAL")
+
+	sinceLabel := iup.Label("")
+	iup.SetAttribute(sinceLabel, "ALIGNMENT", "ACENTER:ACENTER")
+	iup.SetAttribute(sinceLabel, "EXPAND", "HORIZONTAL")
+
+	imsakLabel := iup.Label("")
+	iup.SetAttribute(imsakLabel, "ALIGNMENT", "ACENTER:ACENTER")
+	iup.SetAttribute(imsakLabel, "EXPAND", "HORIZONTAL")
+
+	dstLabel := iup.Label("")
+	iup.SetAttribute(dstLabel, "ALIGNMENT", "ACENTER:ACENTER")
+	iup.SetAttribute(dstLabel, "EXPAND", "HORIZONTAL")
+
+	fajrLabel := iup.Label("")
+	fajrLabel.SetAttributes(map[string]string{
+		"ALIGNMENT": "ACENTER",
+		"EXPAND":    "HORIZONTAL",
+		"FONTSIZE":  scaled(18),
+	})
+
+	// The prayers coming after the next one.
+	upcomingLabel := iup.Label("")
+	iup.SetAttribute(upcomingLabel, "ALIGNMENT", "ACENTER:ACENTER")
+	iup.SetAttribute(upcomingLabel, "EXPAND", "HORIZONTAL")
+
+	nextPrayerFrame := iup.Frame(iup.Vbox(nextPrayer, fajrLabel, upcomingLabel, windowLabel, sinceLabel, imsakLabel, dstLabel))
+	iup.SetAttribute(nextPrayerFrame, "TITLE", "Next Prayer")
+
+	makruhLabel := iup.Label("")
+	makruhLabel.SetAttributes(map[string]string{
+		"FGCOLOR":   "200 0 0",
+		"ALIGNMENT": "ACENTER",
+		"EXPAND":    "HORIZONTAL",
+	})
+
+	qibla, refreshQibla := qiblaPanel()
+	hbox := iup.Hbox(listFrame, nextPrayerFrame, qibla)
+	iup.SetAttribute(hbox, "ALIGNMENT", "ACENTER")
+
+	var dlg iup.Ihandle
+
+	refreshVerse := func(time.Time) {}
+
+	switchLocation := func(loc Location) {
+		SwitchProfile(loc)
+		sched.Reload()
+	}
+	sched.Bus.Subscribe(LocationChanged, func(ev BusEvent) {
+		refreshVerse(time.Now())
+		setTitle(dlg, FormatTitle(sched.Upcoming(), ev.Location))
+		refreshQibla()
+	})
+
+	var sni *SNITray
+	sched.Notify = func(title, message string) {
+		if sni != nil {
+			sni.Notify(title, message)
+			return
+		}
+		notify(dlg, title, message)
+	}
+	sched.NotifyReminder = func(title, message string) {
+		if sni != nil {
+			sni.Notify(title, message, "snooze", "Snooze")
+			return
+		}
+		notify(dlg, title, message)
+	}
+	sched.Flash = func() {
+		iup.SetAttribute(dlg, "HIDETASKBAR", "NO")
+		flashDialog(dlg)
+	}
+	sched.ShowText = showText
+	sched.AdhanDone = func() {
+		iup.PostMessage(dlg, "adhan done", 0, 0, 0)
+	}
+
+	detected := make(chan Location, 1)
+	if travelMode {
+		go watchLocation(detected)
+	}
+
+	configChanged := make(chan bool, 1)
+	if configReload {
+		go watchConfig(configChanged)
+	}
+
+	update := make(chan Release, 1)
+	if checkUpdates {
+		go func() {
+			if r, newer, err := CheckUpdate(); err == nil && newer {
+				update <- r
+			}
+		}()
+	}
+
+	trayItems := func() []TrayItem { return nil }
+	trayCurrent := ""
+	announced := sched.Next.Name
+
+	signals := ShutdownSignals()
+
+	var taskbar *Taskbar
+	sched.Bus.Subscribe(AdhanDue, func(BusEvent) {
+		if taskbar != nil {
+			taskbar.Flash()
+		}
+	})
+
+	timer := iup.Timer()
+	iup.SetAttribute(timer, "TIME", 1000) // 1000ms -> 1s
+	iup.SetCallback(timer, "ACTION_CB", iup.TimerActionFunc(func(ih iup.Ihandle) int {
+		defer sched.Recover("timer")
+		select {
+		case loc := <-detected:
+			if newLoc, ok := promptLocationChange(loc, confirmLocationChange); ok {
+				switchLocation(newLoc)
+			}
+		case <-configChanged:
+			if err := LoadConfig(); err != nil {
+				sched.Notify("Config not applied", err.Error())
+			} else {
+				RestoreProfile()
+				switchLocation(location)
+			}
+		case r := <-update:
+			msg := fmt.Sprintf("Prayer %s is available, you have %s.\nDownload and install it?", r.TagName, version)
+			if iup.Alarm("Update available", msg, "Update", "Later", "") == 1 {
+				if err := InstallUpdate(r); err != nil {
+					iup.MessageError(dlg, "Update failed: "+err.Error())
+				} else {
+					iup.Message("Update installed", "Restart Prayer to use the new version.")
+				}
+			}
+		default:
+		}
+
+		now := time.Now()
+		sched.Tick(now)
+		if sni != nil && sched.Current.Name+"\n"+ProfileLabel(location) != trayCurrent {
+			// The menu names the current prayer and the other profiles.
+			trayCurrent = sched.Current.Name + "\n" + ProfileLabel(location)
+			var labels []string
+			for _, item := range trayItems() {
+				labels = append(labels, item.Label)
+			}
+			sni.SetMenu(labels)
+		}
+		if announceNextPrayer && sched.Next.Name != announced {
+			announced = sched.Next.Name
+			go Announce(fmt.Sprintf("Next prayer is %s at %s", sched.Next.Label(), sched.Next.Time.Format("3:04")))
+		}
+		refreshVerse(now)
+		if iup.GetAttribute(dlg, "VISIBLE") != "YES" {
+			// Nothing to redraw, so sleep until the schedule has something due.
+			setTimer(timer, sched.IdleInterval(now))
+			return iup.DEFAULT
+		}
+		setTimer(timer, time.Second)
+
+		windowRem := sched.CurrentEnd.Sub(now).Round(time.Second)
+		if windowRem > 0 {
+			setTitle(windowLabel, FormatWindow(sched.Current, windowRem))
+		} else {
+			setTitle(windowLabel, "")
+		}
+		if showSinceAdhan {
+			setTitle(sinceLabel, FormatSince(sched.Current, now))
+		}
+
+		warning := ""
+		if p, ok := sched.MakruhAt(now); ok {
+			warning = FormatMakruh(p)
+		}
+		setTitle(makruhLabel, warning)
+
+		setTitle(nextPrayer, FormatNextPrayer(sched.Upcoming()))
+		upcoming := ""
+		if more := UpcomingPrayers(now, upcomingCount, sched.Prayers, sched.Tomorrow); len(more) > 1 {
+			upcoming = "then\n" + FormatUpcoming(more[1:])
+		}
+		setTitle(upcomingLabel, upcoming)
+		setTitle(fajrLabel, FormatTomorrowFajr(sched.Next, now))
+		setTitle(listFrame, TimingsTitle(sched.Prayers, now))
+		setTitle(dlg, FormatTitle(sched.Upcoming(), location))
+		if taskbar != nil {
+			taskbar.SetProgress(sched.Progress(now))
+		}
+		setTitle(imsakLabel, FormatImsak(sched.Imsak))
+		setTitle(dstLabel, sched.DSTNotice)
+		if sched.Imsak.Time.IsZero() {
+			setRow(imsakRow, "")
+		} else {
+			setRow(imsakRow, fmt.Sprint(sched.Imsak))
+		}
+		var extra []string
+		for _, p := range sched.Extra {
+			extra = append(extra, fmt.Sprint(p))
+		}
+		setRow(extraRows, strings.Join(extra, "\n"))
+		return iup.DEFAULT
+	}))
+	iup.SetAttribute(timer, "RUN", "YES")
+
+	// tray icon
+	file, err := os.Open("icon.png")
+	if err != nil {
+		panic(err)
+	}
+	icon, err := png.Decode(file)
+	setIcons(icon)
+
+	closeButton := iup.Button("&Close")
+	closeButton.SetAttributes(map[string]string{
+		"PADDING": pxSize(5, 5),
+		"TIP":     "Quit Prayer (Ctrl+Q)",
+	})
+	iup.SetCallback(closeButton, "ACTION", iup.ActionFunc(func(ih iup.Ihandle) int {
+		return iup.CLOSE
+	}))
+
+	prayedButton := iup.Button("&Prayed")
+	prayedButton.SetAttributes(map[string]string{
+		"PADDING": pxSize(5, 5),
+		"TIP":     "Mark the current prayer as prayed (Ctrl+P)",
+	})
+	iup.SetCallback(prayedButton, "ACTION", iup.ActionFunc(func(ih iup.Ihandle) int {
+		markPrayed(sched.Current)
+		return iup.DEFAULT
+	}))
+
+	statsButton := iup.Button("&Stats")
+	statsButton.SetAttributes(map[string]string{
+		"PADDING": pxSize(5, 5),
+		"TIP":     "Show prayer statistics and qada (Ctrl+I)",
+	})
+	iup.SetCallback(statsButton, "ACTION", iup.ActionFunc(func(ih iup.Ihandle) int {
+		showStats()
+		return iup.DEFAULT
+	}))
+
+	backupButton := iup.Button("&Backup")
+	backupButton.SetAttributes(map[string]string{
+		"PADDING": pxSize(5, 5),
+		"TIP":     "Export or import settings and the prayer log (Ctrl+B)",
+	})
+	iup.SetCallback(backupButton, "ACTION", iup.ActionFunc(func(ih iup.Ihandle) int {
+		showBackup(func() {
+			switchLocation(location)
+		})
+		return iup.DEFAULT
+	}))
+
+	snoozeButton := iup.Button("S&nooze")
+	snoozeButton.SetAttributes(map[string]string{
+		"PADDING": pxSize(5, 5),
+		"TIP":     "Repeat the last reminder later (Ctrl+S)",
+	})
+	iup.SetCallback(snoozeButton, "ACTION", iup.ActionFunc(func(ih iup.Ihandle) int {
+		sched.Snooze(snoozeDuration)
+		return iup.DEFAULT
+	}))
+
+	helpButton := iup.Button("&Help")
+	helpButton.SetAttributes(map[string]string{
+		"PADDING": pxSize(5, 5),
+		"TIP":     "Keyboard shortcuts (F1)",
+	})
+	iup.SetCallback(helpButton, "ACTION", iup.ActionFunc(func(ih iup.Ihandle) int {
+		iup.Message("Keyboard shortcuts", shortcutsHelp)
+		return iup.DEFAULT
+	}))
+
+	buttons := iup.Hbox(closeButton, prayedButton, snoozeButton, statsButton, backupButton, helpButton)
+	if len(cities) > 0 {
+		citiesButton := iup.Button("C&ities")
+		citiesButton.SetAttributes(map[string]string{
+			"PADDING": pxSize(5, 5),
+			"TIP":     "Show the next prayer in other cities",
+		})
+		iup.SetCallback(citiesButton, "ACTION", iup.ActionFunc(func(ih iup.Ihandle) int {
+			showCitiesDashboard()
+			return iup.DEFAULT
+		}))
+		iup.Append(buttons, citiesButton)
+	}
+
+	prayersTab := iup.Vbox(hbox, makruhLabel)
+	if showVerse {
+		verse, updateVerse := versePanel()
+		refreshVerse = updateVerse
+		iup.Append(prayersTab, verse)
+	}
+	iup.SetAttribute(prayersTab, "TABTITLE", "Prayers")
+
+	historyTab, refreshHistory := historyPanel()
+	iup.SetAttribute(historyTab, "TABTITLE", "History")
+
+	tabs := iup.Tabs(prayersTab, historyTab)
+	iup.SetCallback(tabs, "TABCHANGE_CB", iup.TabChangeFunc(func(ih, newTab, oldTab iup.Ihandle) int {
+		if newTab == historyTab {
+			refreshHistory()
+		}
+		return iup.DEFAULT
+	}))
+
+	vbox := iup.Vbox(tabs, buttons)
+	vbox.SetAttributes(map[string]string{
+		"ALIGNMENT": "ACENTER",
+		"MARGIN":    pxSize(2, 2),
+	})
+
+	dlg = iup.Dialog(vbox)
+	dlg.SetAttributes(map[string]string{
+		"TITLE":   FormatTitle(sched.Upcoming(), location),
+		"ICON":    "windowicon",
+		"TOPMOST": "YES",
+	})
+	if useSNITray() {
+		if sni, err = StartSNITray("Prayer times", icon); err != nil {
+			sni = nil
+		}
+	}
+
+	// The other goroutines post what they have to the dialog, which gets it
+	// at once rather than at the next tick, as the timer sleeps between the
+	// events of the schedule while the window is hidden.
+	iup.SetCallback(dlg, "POSTMESSAGE_CB", iup.PostMessageFunc(func(ih iup.Ihandle, s string, i int, d float64, p *cgo.Handle) int {
+		defer sched.Recover("message")
+		switch s {
+		case "queued":
+			sched.RunQueued()
+		case "show":
+			iup.SetAttribute(dlg, "HIDETASKBAR", "NO")
+			iup.Show(dlg)
+		case "menu":
+			if items := trayItems(); i < len(items) && items[i].Action != nil {
+				items[i].Action()
+			}
+		case "snooze":
+			sched.Snooze(snoozeDuration)
+		case "adhan done":
+			if focusMode.Enabled {
+				showFocus(sched.Current)
+			}
+			if showDuaAfterAdhan {
+				showDua()
+			}
+		case "quit":
+			iup.ExitLoop()
+		}
+		return iup.DEFAULT
+	}))
+	iup.SetCallback(dlg, "SHOW_CB", iup.ShowFunc(func(ih iup.Ihandle, state int) int {
+		if state == iup.SHOW {
+			// Tick every second again for the countdown.
+			setTimer(timer, time.Second)
+		}
+		return iup.DEFAULT
+	}))
+	post := func(s string, i int) { iup.PostMessage(dlg, s, i, 0, 0) }
+	sched.SetWake(func() { post("queued", 0) })
+	go func() {
+		for range raise {
+			post("show", 0)
+		}
+	}()
+	go func() {
+		<-signals
+		post("quit", 0)
+	}()
+	if sni != nil {
+		go func() {
+			for {
+				select {
+				case <-sni.Activated:
+					post("show", 0)
+				case i := <-sni.Clicked:
+					post("menu", i)
+				case action := <-sni.Action:
+					if action == "snooze" {
+						post("snooze", 0)
+					}
+				}
+			}
+		}()
+	}
+	if sni == nil {
+		dlg.SetAttributes(map[string]string{
+			"TRAY":      "YES",
+			"TRAYIMAGE": "icon",
+		})
+	}
+
+	iup.SetCallback(dlg, "K_ANY", iup.KAnyFunc(func(ih iup.Ihandle, c int) int {
+		switch c {
+		case iup.K_ESC:
+			StopSound()
+			sched.Dismiss()
+		case iup.XKeyCtrl(iup.K_S):
+			sched.Snooze(snoozeDuration)
+		case iup.XKeyCtrl(iup.K_L):
+			switchLocation(nextProfile())
+		case iup.XKeyCtrl(iup.K_P):
+			markPrayed(sched.Current)
+		case iup.XKeyCtrl(iup.K_T):
+			showTasbih()
+		case iup.XKeyCtrl(iup.K_I):
+			showStats()
+		case iup.XKeyCtrl(iup.K_B):
+			showBackup(func() {
+				switchLocation(location)
+			})
+		case iup.XKeyCtrl(iup.K_W):
+			iup.SetAttribute(ih, "HIDETASKBAR", "YES")
+		case iup.XKeyCtrl(iup.K_Q):
+			return iup.CLOSE
+		case iup.K_F1:
+			iup.Message("Keyboard shortcuts", shortcutsHelp)
+		default:
+			return iup.CONTINUE
+		}
+		return iup.IGNORE
+	}))
+
+	iup.SetCallback(dlg, "CLOSE_CB", iup.CloseFunc(func(ih iup.Ihandle) int {
+		iup.SetAttribute(ih, "HIDETASKBAR", "YES")
+		return iup.IGNORE
+	}))
+
+	trayItems = func() []TrayItem {
+		items := []TrayItem{
+			{"Mark " + sched.Current.Label() + " as prayed", func() { markPrayed(sched.Current) }},
+			{"Tasbih", showTasbih},
+			{"Stop sound", func() { StopSound(); sched.Dismiss() }},
+			{"Snooze", func() { sched.Snooze(snoozeDuration) }},
+		}
+		for _, p := range profiles {
+			if p.Is(location) && p.CalcMethod() == location.CalcMethod() {
+				continue
+			}
+			if len(items) == 4 {
+				items = append(items, TrayItem{Label: ""})
+			}
+			p := p
+			items = append(items, TrayItem{"Switch to: " + ProfileLabel(p), func() { switchLocation(p) }})
+		}
+		return append(items,
+			TrayItem{Label: ""},
+			TrayItem{"Hide", func() { iup.SetAttribute(dlg, "HIDETASKBAR", "YES") }},
+		)
+	}
+
+	trayMenu := func() iup.Ihandle {
+		menu := iup.Menu()
+		for _, item := range trayItems() {
+			if item.Label == "" {
+				iup.Append(menu, iup.Separator())
+				continue
+			}
+			action := item.Action
+			mi := iup.Item(item.Label)
+			iup.SetCallback(mi, "ACTION", iup.ActionFunc(func(ih iup.Ihandle) int {
+				action()
+				return iup.DEFAULT
+			}))
+			iup.Append(menu, mi)
+		}
+		return menu
+	}
+
+	iup.SetCallback(dlg, "TRAYCLICK_CB",
+		iup.TrayClickFunc(func(ih iup.Ihandle, but, pressed, dclick int) int {
+			if pressed == 1 {
+				switch but {
+				case 1:
+					iup.SetAttribute(ih, "HIDETASKBAR", "NO")
+				case 3:
+					menu := trayMenu()
+					iup.Popup(menu, iup.MOUSEPOS, iup.MOUSEPOS)
+					menu.Destroy()
+				}
+			}
+			return iup.DEFAULT
+		}))
+
+	iup.Show(dlg)
+	if startMinimized {
+		iup.SetAttribute(dlg, "HIDETASKBAR", "YES")
+	}
+	if taskbarProgress {
+		taskbar, _ = NewTaskbar(dlg.GetPtr("HWND"))
+	}
+
+	code := iup.MainLoop()
+	// Remove the tray icon now, rather than it staying until the mouse
+	// passes over it.
+	if sni != nil {
+		sni.Close()
+	} else {
+		iup.SetAttribute(dlg, "TRAY", "NO")
+	}
+	sched.Shutdown()
+	return code
+}
+
+const shortcutsHelp = `Esc	Stop the sound playing and the escalation
+Ctrl+S	Snooze the last reminder
+Ctrl+P	Mark the current prayer as prayed
+Ctrl+L	Switch to the next location profile
+Ctrl+T	Tasbih counter
+Ctrl+I	Statistics
+Ctrl+B	Backup and settings
+Ctrl+W	Hide to the tray
+Ctrl+Q	Quit
+F1	This help
+
+In the prayer list, Enter marks a past prayer as prayed.
+Alt and the underlined letter presses a button.`
+
+// setTitle changes the title of ih only when it differs, to spare redraws
+// when the countdown doesn't show seconds.
+func setTitle(ih iup.Ihandle, title string) {
+	if iup.GetAttribute(ih, "TITLE") != title {
+		iup.SetAttribute(ih, "TITLE", title)
+	}
+}
+
+// setRow sets the title of a label that is hidden while it's empty.
+func setRow(ih iup.Ihandle, title string) {
+	setTitle(ih, title)
+	visible, floating := "YES", "NO"
+	if title == "" {
+		visible, floating = "NO", "YES"
+	}
+	if iup.GetAttribute(ih, "VISIBLE") != visible {
+		iup.SetAttribute(ih, "VISIBLE", visible)
+		iup.SetAttribute(ih, "FLOATING", floating)
+		iup.Refresh(ih)
+	}
+}
+
+func confirmLocationChange(msg string) bool {
+	return iup.Alarm("Location changed", msg, "Switch", "Keep", "") == 1
+}
+
+// setTimer makes timer fire every d, restarting it when d changed.
+func setTimer(timer iup.Ihandle, d time.Duration) {
+	ms := strconv.Itoa(int(d / time.Millisecond))
+	if iup.GetAttribute(timer, "TIME") != ms {
+		iup.SetAttribute(timer, "RUN", "NO")
+		iup.SetAttribute(timer, "TIME", ms)
+		iup.SetAttribute(timer, "RUN", "YES")
+	}
+}
+
+// notify shows a balloon on the tray icon of dlg.
+func notify(dlg iup.Ihandle, title, text string) {
+	dlg.SetAttributes(map[string]string{
+		"TRAYTIPBALLOONTITLE": title,
+		"TRAYTIPBALLOON":      "YES",
+		"TRAYTIP":             text,
+	})
+}
@@ -0,0 +1,102 @@
+package main
+
+import "time"
+
+// Hijri months some features depend on.
+const (
+	Muharram   = 1
+	Ramadan    = 9
+	Shawwal    = 10
+	DhulHijjah = 12
+)
+
+// Behaviors following the Hijri month, from the API's hijri dates.
+// RamadanMode has a suhoor alarm every day of Ramadan and a reminder at
+// Maghrib to break the fast, DhulHijjah reminds of the first ten days each
+// morning, Offset after Fajr, and Muharram suggests fasting Tasu'a and
+// Ashura. Fasting on Eid and the days of Tashreeq is never suggested.
+var hijriMonths = struct {
+	RamadanMode bool
+	DhulHijjah  bool
+	Muharram    bool
+	Offset      Duration
+	Sound       string
+}{
+	RamadanMode: true,
+	DhulHijjah:  true,
+	Muharram:    true,
+	Offset:      Duration(time.Hour),
+	Sound:       "tasbih.wav",
+}
+
+// Ramadan reports whether h is in Ramadan.
+func (h HijriDate) Ramadan() bool {
+	return h.Month == Ramadan
+}
+
+// Eid reports whether h is Eid al-Fitr or Eid al-Adha, and which.
+func (h HijriDate) Eid() (string, bool) {
+	switch {
+	case h.Month == Shawwal && h.Day == 1:
+		return "Eid al-Fitr", true
+	case h.Month == DhulHijjah && h.Day == 10:
+		return "Eid al-Adha", true
+	}
+	return "", false
+}
+
+// Tashreeq reports whether h is one of the days of Tashreeq, the three
+// after Eid al-Adha.
+func (h HijriDate) Tashreeq() bool {
+	return h.Month == DhulHijjah && h.Day >= 11 && h.Day <= 13
+}
+
+// NoFasting reports whether fasting on h is forbidden.
+func (h HijriDate) NoFasting() bool {
+	_, eid := h.Eid()
+	return eid || h.Tashreeq()
+}
+
+// hijriFast returns the fast of h following its month, if any.
+func hijriFast(h HijriDate) (string, bool) {
+	switch {
+	case hijriMonths.RamadanMode && h.Ramadan():
+		return "Ramadan", true
+	case hijriMonths.DhulHijjah && h.Month == DhulHijjah && h.Day == 9:
+		return "Arafah", true
+	case hijriMonths.Muharram && h.Month == Muharram && h.Day == 9:
+		return "Tasu'a", true
+	case hijriMonths.Muharram && h.Month == Muharram && h.Day == 10:
+		return "Ashura", true
+	}
+	return "", false
+}
+
+func hijriEvents(loc Location, day time.Time, timings map[string]time.Time) []Event {
+	h := Hijri(loc, day)
+	var events []Event
+
+	if hijriMonths.RamadanMode && h.Ramadan() {
+		if t, ok := timings["Maghrib"]; ok {
+			events = append(events, Event{
+				Name:    "Iftar",
+				Time:    t,
+				Message: "Time to break the fast",
+				Sound:   hijriMonths.Sound,
+			})
+		}
+	}
+
+	if hijriMonths.DhulHijjah && h.Month == DhulHijjah && h.Day <= 10 {
+		if t, ok := EventTime(day, timings, "Fajr", time.Duration(hijriMonths.Offset), ""); ok {
+			msg := "The first ten days of Dhul-Hijjah: make plenty of takbeer, tahleel and tahmeed"
+			if h.Day == 9 {
+				msg = "Today is the day of Arafah"
+			} else if h.Day == 10 {
+				msg = "Eid Mubarak"
+			}
+			events = append(events, Event{Name: "Dhul-Hijjah", Time: t, Message: msg, Sound: hijriMonths.Sound})
+		}
+	}
+	return events
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+var historyLength = 200 // rows shown in the history tab
+
+// Alert is a reminder, adhan or event that fired.
+type Alert struct {
+	Time    time.Time
+	Kind    string
+	Name    string
+	Message string
+	Sound   string
+	Played  bool
+}
+
+func (a Alert) String() string {
+	sound := ""
+	if a.Sound != "" {
+		sound = "sound not played"
+		if a.Played {
+			sound = "sound played"
+		}
+	}
+	return fmt.Sprintf("%s  %-9s %-14s %s", a.Time.Format("2006-01-02 15:04:05"), a.Kind, PrayerLabel(a.Name), sound)
+}
+
+// alert records an alert of kind in the history and publishes it, for its
+// message to be shown as a notification titled name and its sound played,
+// when not empty.
+func (s *Scheduler) alert(kind, name, message, sound string) {
+	s.alertVolume(kind, name, message, sound, 0)
+}
+
+// alertVolume is alert playing sound at volume, in powers of two from the
+// file's own.
+func (s *Scheduler) alertVolume(kind, name, message, sound string, volume float64) {
+	now := time.Now()
+	res, err := db.Exec(`INSERT INTO alerts (time, kind, name, message, sound) VALUES (?, ?, ?, ?, ?)`,
+		now.Format(time.RFC3339), kind, name, message, sound)
+	if err != nil {
+		panic(err)
+	}
+	id, _ := res.LastInsertId()
+	s.Bus.Publish(BusEvent{
+		Topic:  alertTopic(kind),
+		Alert:  Alert{Time: now, Kind: kind, Name: name, Message: message, Sound: sound},
+		Volume: volume,
+		id:     id,
+	})
+}
+
+// AlertHistory returns the last n alerts, newest first.
+func AlertHistory(n int) []Alert {
+	rows, err := db.Query(`SELECT time, kind, name, message, sound, played FROM alerts
+		ORDER BY id DESC LIMIT ?`, n)
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+
+	var alerts []Alert
+	for rows.Next() {
+		var a Alert
+		var t string
+		if err := rows.Scan(&t, &a.Kind, &a.Name, &a.Message, &a.Sound, &a.Played); err != nil {
+			panic(err)
+		}
+		a.Time, _ = time.Parse(time.RFC3339, t)
+		alerts = append(alerts, a)
+	}
+	return alerts
+}
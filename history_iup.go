@@ -0,0 +1,22 @@
+//go:build !gio
+
+package main
+
+import "github.com/gen2brain/iup-go/iup"
+
+// historyPanel returns the history list and a function to reload it.
+func historyPanel() (iup.Ihandle, func()) {
+	list := iup.List()
+	list.SetAttributes(map[string]string{
+		"EXPAND":       "YES",
+		"VISIBLELINES": "8",
+	})
+
+	refresh := func() {
+		iup.SetAttribute(list, "REMOVEITEM", "ALL")
+		for _, a := range AlertHistory(historyLength) {
+			iup.SetAttribute(list, "APPENDITEM", a.String())
+		}
+	}
+	return list, refresh
+}
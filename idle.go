@@ -0,0 +1,50 @@
+package main
+
+import "time"
+
+// While the window is hidden the frontends tick only as often as the
+// schedule needs, waking idleMargin before each of its events and ticking
+// every second until it's past, as its checks are to the second. They
+// still wake every maxIdle, for the config and location watchers.
+const (
+	idleMargin = 3 * time.Second
+	maxIdle    = time.Minute
+)
+
+// NextWake returns the time of the next thing Tick fires after now, or
+// maxIdle from now if there's none sooner.
+func (s *Scheduler) NextWake(now time.Time) time.Time {
+	wake := now.Add(maxIdle)
+	at := func(t time.Time) {
+		if t.After(now) && t.Before(wake) {
+			wake = t
+		}
+	}
+
+	for _, r := range RemindersFor(s.Next.Name) {
+		at(s.Next.Time.Add(-time.Duration(r.Before)))
+	}
+	at(s.Next.Time.Add(-time.Second)) // the adhan
+	at(s.CurrentEnd.Add(-time.Duration(windowAlert.Before)))
+	at(s.CurrentEnd)
+	if escalation.Enabled {
+		at(s.Current.Time.Add(time.Duration(escalation.After) * time.Duration(s.escalations+1)))
+	}
+	for _, ev := range s.events {
+		at(ev.Time)
+	}
+	at(s.snoozed)
+	y, m, d := now.Date()
+	at(time.Date(y, m, d+1, 0, 0, 0, 0, now.Location()))
+	return wake
+}
+
+// IdleInterval returns how long the frontend can wait before the next Tick
+// while hidden.
+func (s *Scheduler) IdleInterval(now time.Time) time.Duration {
+	d := s.NextWake(now).Sub(now) - idleMargin
+	if d < time.Second {
+		return time.Second
+	}
+	return d.Truncate(time.Second)
+}
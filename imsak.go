@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Show Imsak, when eating stops before Fajr, with its own row and countdown
+// during Ramadan, and alert Before it.
+var imsak = struct {
+	Enabled bool
+	Before  Duration
+	Sound   string
+}{
+	Enabled: true,
+	Sound:   "tasbih.wav",
+}
+
+// ImsakTime returns Imsak of day, if it's shown that day.
+func ImsakTime(h HijriDate, timings map[string]time.Time) (Prayer, bool) {
+	t, ok := timings["Imsak"]
+	if !imsak.Enabled || !h.Ramadan() || !ok {
+		return Prayer{}, false
+	}
+	return Prayer{Name: "Imsak", Time: t}, true
+}
+
+// FormatImsak counts down to Imsak, or is empty once it passed.
+func FormatImsak(p Prayer) string {
+	if p.Time.IsZero() || !time.Now().Before(p.Time) {
+		return ""
+	}
+	return fmt.Sprintf("%s %s", p.Label(), FormatUntil("in", p.Time))
+}
+
+func imsakEvents(loc Location, day time.Time, timings map[string]time.Time) []Event {
+	p, ok := ImsakTime(Hijri(loc, day), timings)
+	if !ok {
+		return nil
+	}
+
+	msg := "Imsak, stop eating and drinking"
+	if imsak.Before > 0 {
+		msg = fmt.Sprintf("Imsak in %v minutes", time.Duration(imsak.Before).Minutes())
+	}
+	return []Event{{
+		Name:    "Imsak",
+		Time:    p.Time.Add(-time.Duration(imsak.Before)),
+		Message: msg,
+		Sound:   imsak.Sound,
+	}}
+}
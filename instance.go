@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// Address the running instance listens on. Launching the app again connects
+// to it and asks it to show its window instead of starting a second scheduler.
+const instanceAddr = "127.0.0.1:47413"
+
+// SingleInstance claims the instance lock. The returned channel receives a
+// value whenever another launch asks to show the window. It returns false
+// when another instance is already running, after asking it to show itself.
+func SingleInstance() (<-chan bool, bool) {
+	raise := make(chan bool, 1)
+
+	ln, err := net.Listen("tcp", instanceAddr)
+	if err != nil {
+		if conn, err := net.Dial("tcp", instanceAddr); err == nil {
+			fmt.Fprintln(conn, "show")
+			conn.Close()
+			return nil, false
+		}
+		fmt.Fprintln(os.Stderr, "Can't claim the instance lock:", err)
+		return raise, true
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				// Wait out a temporary error like running out of files,
+				// as net/http's server does.
+				if ne, ok := err.(interface{ Temporary() bool }); ok && ne.Temporary() {
+					time.Sleep(100 * time.Millisecond)
+					continue
+				}
+				fmt.Fprintln(os.Stderr, "instance:", err)
+				return
+			}
+			// A launch writes its line at once, so one that doesn't isn't
+			// waited for.
+			conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+			line, _ := bufio.NewReader(conn).ReadString('\n')
+			conn.Close()
+			if line == "show\n" {
+				select {
+				case raise <- true:
+				default:
+				}
+			}
+		}
+	}()
+	return raise, true
+}
@@ -0,0 +1,32 @@
+package main
+
+import "time"
+
+// Friday reminder to read Surah Al-Kahf, Offset after the After timing, or
+// at the clock time At ("15:04") when set.
+var kahfReminder = struct {
+	Enabled bool
+	After   string
+	Offset  Duration
+	At      string
+	Message string
+	Sound   string
+}{
+	Enabled: true,
+	After:   "Fajr",
+	Offset:  Duration(time.Hour),
+	Message: "Remember to read Surah Al-Kahf today",
+}
+
+func kahfEvents(day time.Time, timings map[string]time.Time) []Event {
+	r := kahfReminder
+	if !r.Enabled || day.Weekday() != time.Friday {
+		return nil
+	}
+
+	t, ok := EventTime(day, timings, r.After, time.Duration(r.Offset), r.At)
+	if !ok {
+		return nil
+	}
+	return []Event{{Name: "Surah Al-Kahf", Time: t, Message: r.Message, Sound: r.Sound}}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// rateLimiter is a token bucket per client address.
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64 // tokens a second
+	burst   float64
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64) *rateLimiter {
+	return &rateLimiter{rate: rate, burst: 2 * rate, buckets: make(map[string]*bucket)}
+}
+
+// Allow takes a token from the bucket of addr, reporting whether there was
+// one. A rate of 0 allows everything.
+func (l *rateLimiter) Allow(addr string) bool {
+	if l.rate <= 0 {
+		return true
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	// Forget the clients whose buckets have filled up again.
+	if now.Sub(l.swept) > time.Minute {
+		for h, b := range l.buckets {
+			if now.Sub(b.last).Seconds()*l.rate >= l.burst {
+				delete(l.buckets, h)
+			}
+		}
+		l.swept = now
+	}
+
+	b, ok := l.buckets[host]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[host] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// limitRate answers 429 to the clients going over the limit of l.
+func limitRate(l *rateLimiter, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.Allow(r.RemoteAddr) {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// grpcLimitRate returns a check failing the calls of the clients going
+// over the limit of l.
+func grpcLimitRate(l *rateLimiter) func(context.Context) error {
+	return func(ctx context.Context) error {
+		p, ok := peer.FromContext(ctx)
+		if ok && !l.Allow(p.Addr.String()) {
+			return status.Error(codes.ResourceExhausted, "too many requests")
+		}
+		return nil
+	}
+}
+
+// grpcInterceptors returns the interceptors failing the calls for which
+// one of checks fails.
+func grpcInterceptors(checks ...func(context.Context) error) []grpc.ServerOption {
+	check := func(ctx context.Context) error {
+		for _, c := range checks {
+			if err := c(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := check(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := check(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
+
+// responseCache keeps the server's responses for CacheFor, so clients
+// polling often don't recompute them.
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+func newResponseCache() *responseCache {
+	return &responseCache{entries: make(map[string]cacheEntry)}
+}
+
+// Get returns the value of key, calling f for it when it's not kept or has
+// expired. Errors aren't kept.
+func (c *responseCache) Get(key string, f func() (interface{}, error)) (interface{}, error) {
+	now := time.Now()
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		return e.value, nil
+	}
+
+	v, err := f()
+	if err != nil || server.CacheFor <= 0 {
+		return v, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cacheEntry{v, now.Add(time.Duration(server.CacheFor))}
+	return v, nil
+}
+
+// Clear forgets the kept responses.
+func (c *responseCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]cacheEntry)
+}
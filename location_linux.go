@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const geoclue = "org.freedesktop.GeoClue2"
+
+// OSLocation asks GeoClue for the current location over D-Bus. The desktop
+// GeoClue agent takes care of asking the user for consent.
+func OSLocation() (Location, error) {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return Location{}, err
+	}
+
+	var clientPath dbus.ObjectPath
+	manager := conn.Object(geoclue, "/org/freedesktop/GeoClue2/Manager")
+	if err := manager.Call(geoclue+".Manager.GetClient", 0).Store(&clientPath); err != nil {
+		return Location{}, err
+	}
+
+	client := conn.Object(geoclue, clientPath)
+	if err := client.SetProperty(geoclue+".Client.DesktopId", dbus.MakeVariant("prayer")); err != nil {
+		return Location{}, err
+	}
+	// GCLUE_ACCURACY_LEVEL_CITY is enough for prayer times.
+	if err := client.SetProperty(geoclue+".Client.RequestedAccuracyLevel", dbus.MakeVariant(uint32(4))); err != nil {
+		return Location{}, err
+	}
+
+	if err := conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(clientPath),
+		dbus.WithMatchInterface(geoclue+".Client"),
+		dbus.WithMatchMember("LocationUpdated"),
+	); err != nil {
+		return Location{}, err
+	}
+	signals := make(chan *dbus.Signal, 1)
+	conn.Signal(signals)
+	defer conn.RemoveSignal(signals)
+
+	if err := client.Call(geoclue+".Client.Start", 0).Err; err != nil {
+		return Location{}, err
+	}
+	defer client.Call(geoclue+".Client.Stop", 0)
+
+	var locationPath dbus.ObjectPath
+	select {
+	case sig := <-signals:
+		var ok bool
+		if len(sig.Body) > 1 {
+			locationPath, ok = sig.Body[1].(dbus.ObjectPath)
+		}
+		if !ok {
+			return Location{}, errors.New("geoclue: malformed LocationUpdated signal")
+		}
+	case <-time.After(30 * time.Second):
+		return Location{}, errors.New("geoclue: timed out waiting for location")
+	}
+
+	var loc Location
+	l := conn.Object(geoclue, locationPath)
+	for prop, dst := range map[string]interface{}{
+		"Latitude":    &loc.Latitude,
+		"Longitude":   &loc.Longitude,
+		"Description": &loc.Name,
+	} {
+		v, err := l.GetProperty(geoclue + ".Location." + prop)
+		if err != nil {
+			return Location{}, err
+		}
+		if err := v.Store(dst); err != nil {
+			return Location{}, err
+		}
+	}
+	return loc, nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !windows
+
+package main
+
+import "errors"
+
+func OSLocation() (Location, error) {
+	return Location{}, errors.New("location services are not supported on this platform")
+}
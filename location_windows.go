@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"syscall"
+)
+
+// Reads the location through the Windows Location API. Windows shows its own
+// consent prompt and honours the privacy settings.
+const geoScript = `
+Add-Type -AssemblyName System.Device
+$w = New-Object System.Device.Location.GeoCoordinateWatcher
+$w.Start()
+$n = 0
+while ($w.Status -ne 'Ready' -and $w.Permission -ne 'Denied' -and $n -lt 300) {
+	Start-Sleep -Milliseconds 100
+	$n++
+}
+$c = $w.Position.Location
+if ($w.Permission -eq 'Denied' -or $c.IsUnknown) { exit 1 }
+[string]::Format([Globalization.CultureInfo]::InvariantCulture, '{0} {1}', $c.Latitude, $c.Longitude)
+`
+
+// OSLocation asks the Windows Location API for the current location.
+func OSLocation() (Location, error) {
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", geoScript)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+
+	out, err := cmd.Output()
+	if err != nil {
+		return Location{}, errors.New("location services unavailable or denied")
+	}
+
+	var loc Location
+	if _, err := fmt.Sscan(string(out), &loc.Latitude, &loc.Longitude); err != nil {
+		return Location{}, err
+	}
+	return loc, nil
+}
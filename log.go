@@ -0,0 +1,209 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var logPath = "./prayer.db"
+
+var db *sql.DB
+
+func OpenLog() {
+	var err error
+	db, err = sql.Open("sqlite3", logPath)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS prayers (
+		date      TEXT NOT NULL,
+		prayer    TEXT NOT NULL,
+		prayed_at TEXT NOT NULL,
+		on_time   INTEGER NOT NULL,
+		PRIMARY KEY (date, prayer)
+	)`)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS qada (
+		prayer TEXT PRIMARY KEY,
+		count  INTEGER NOT NULL
+	)`)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS alerts (
+		id      INTEGER PRIMARY KEY,
+		time    TEXT NOT NULL,
+		kind    TEXT NOT NULL,
+		name    TEXT NOT NULL,
+		message TEXT NOT NULL,
+		sound   TEXT NOT NULL,
+		played  INTEGER NOT NULL DEFAULT 0
+	)`)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS state (
+		key   TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	)`)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// CurrentPrayer returns the prayer whose time has come most recently, which
+// is yesterday's Isha before today's Fajr.
+func CurrentPrayer(loc Location, prayers Prayers) Prayer {
+	now := time.Now()
+	for i := len(prayers) - 1; i >= 0; i-- {
+		if !now.Before(prayers[i].Time) {
+			return prayers[i]
+		}
+	}
+	previous := PrayerTimings(loc, prayers[0].Time.AddDate(0, 0, -1))
+	return previous[len(previous)-1]
+}
+
+// MarkPrayed records p as prayed now, on time if its window hasn't ended.
+// A late prayer is a made up qada.
+func MarkPrayed(loc Location, p Prayer) {
+	now := time.Now()
+	onTime := now.Before(WindowEnd(loc, p))
+
+	if !onTime && !IsPrayed(p) {
+		AddQada(p.Name, -1)
+	}
+
+	_, err := db.Exec(`INSERT OR REPLACE INTO prayers VALUES (?, ?, ?, ?)`,
+		p.Time.Format(time.DateOnly), p.Name, now.Format(time.RFC3339), onTime)
+	if err != nil {
+		panic(err)
+	}
+}
+
+type LoggedPrayer struct {
+	Date     string
+	Prayer   string
+	PrayedAt string
+	OnTime   bool
+}
+
+func LoggedPrayers() []LoggedPrayer {
+	rows, err := db.Query(`SELECT date, prayer, prayed_at, on_time FROM prayers ORDER BY date, prayed_at`)
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+
+	var prayers []LoggedPrayer
+	for rows.Next() {
+		var p LoggedPrayer
+		if err := rows.Scan(&p.Date, &p.Prayer, &p.PrayedAt, &p.OnTime); err != nil {
+			panic(err)
+		}
+		prayers = append(prayers, p)
+	}
+	return prayers
+}
+
+func ImportPrayers(prayers []LoggedPrayer) {
+	for _, p := range prayers {
+		_, err := db.Exec(`INSERT OR REPLACE INTO prayers VALUES (?, ?, ?, ?)`,
+			p.Date, p.Prayer, p.PrayedAt, p.OnTime)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func IsPrayed(p Prayer) bool {
+	var n int
+	err := db.QueryRow(`SELECT COUNT(*) FROM prayers WHERE date = ? AND prayer = ?`,
+		p.Time.Format(time.DateOnly), p.Name).Scan(&n)
+	if err != nil {
+		panic(err)
+	}
+	return n > 0
+}
+
+// WindowEnded counts p as a qada if it wasn't prayed in its window.
+func WindowEnded(p Prayer) {
+	if !IsPrayed(p) {
+		AddQada(p.Name, 1)
+	}
+}
+
+// AddQada adds n, which may be negative, to the missed prayers of name.
+// The count never drops below zero.
+func AddQada(name string, n int) {
+	_, err := db.Exec(`INSERT INTO qada VALUES (?, MAX(?, 0))
+		ON CONFLICT (prayer) DO UPDATE SET count = MAX(count + ?, 0)`, name, n, n)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func SetQada(name string, n int) {
+	_, err := db.Exec(`INSERT OR REPLACE INTO qada VALUES (?, MAX(?, 0))`, name, n)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func QadaCounts() map[string]int {
+	counts := make(map[string]int, len(prayerNames))
+
+	rows, err := db.Query(`SELECT prayer, count FROM qada`)
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var n int
+		if err := rows.Scan(&name, &n); err != nil {
+			panic(err)
+		}
+		counts[name] = n
+	}
+	return counts
+}
+
+// CloseLog closes the prayer log, writing out what's pending.
+func CloseLog() {
+	if err := db.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, "log:", err)
+	}
+}
+
+// State returns what was saved under key by SetState, or "".
+func State(key string) string {
+	var value string
+	err := db.QueryRow(`SELECT value FROM state WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return ""
+	}
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// SetState saves value under key, kept across restarts.
+func SetState(key, value string) {
+	_, err := db.Exec(`INSERT OR REPLACE INTO state VALUES (?, ?)`, key, value)
+	if err != nil {
+		panic(err)
+	}
+}
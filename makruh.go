@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Times when voluntary prayer is discouraged: after sunrise, before Dhuhr at
+// zawal, and before Maghrib.
+var (
+	makruhWarnings = true
+	makruhSound    = "" // played when a makruh time starts
+	afterSunrise   = 15 * time.Minute
+	beforeDhuhr    = 10 * time.Minute
+	beforeMaghrib  = 15 * time.Minute
+)
+
+type Period struct {
+	Name       string
+	Start, End time.Time
+}
+
+func (p Period) Contains(t time.Time) bool {
+	return !t.Before(p.Start) && t.Before(p.End)
+}
+
+func MakruhTimes(timings map[string]time.Time) []Period {
+	if !makruhWarnings {
+		return nil
+	}
+
+	var periods []Period
+	if t, ok := timings["Sunrise"]; ok {
+		periods = append(periods, Period{"Sunrise", t, t.Add(afterSunrise)})
+	}
+	if t, ok := timings["Dhuhr"]; ok {
+		periods = append(periods, Period{"Zawal", t.Add(-beforeDhuhr), t})
+	}
+	if t, ok := timings["Maghrib"]; ok {
+		periods = append(periods, Period{"Sunset", t.Add(-beforeMaghrib), t})
+	}
+	return periods
+}
+
+func makruhEvents(timings map[string]time.Time) []Event {
+	var events []Event
+	for _, p := range MakruhTimes(timings) {
+		events = append(events, Event{
+			Name:    "Makruh time",
+			Time:    p.Start,
+			Message: FormatMakruh(p),
+			Sound:   makruhSound,
+		})
+	}
+	return events
+}
+
+func FormatMakruh(p Period) string {
+	return fmt.Sprintf("Prayer is discouraged until %s (%s)", p.End.Format("03:04"), PrayerLabel(p.Name))
+}
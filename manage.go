@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// started is when the app started, for its uptime.
+var started = time.Now()
+
+// Health is how a server is doing, for monitoring kiosks.
+type Health struct {
+	Version  string
+	Started  time.Time
+	Uptime   string
+	Location string
+	Next     StatusPrayer
+	Muted    bool
+}
+
+// ServerHealth returns the Health of s, an error if its scheduler isn't
+// running.
+func ServerHealth(s *Scheduler) (Health, error) {
+	h := Health{Version: version, Started: started, Uptime: time.Since(started).Round(time.Second).String()}
+	err := call(s, func() {
+		h.Location = location.Name
+		h.Next = statusPrayer(s.Upcoming(), time.Now())
+		h.Muted = s.Muted
+	})
+	if err != nil {
+		return Health{}, err
+	}
+	return h, nil
+}
+
+// actionHandler runs f, a change of s, for POST requests and replies with
+// the Health after it.
+func actionHandler(s *Scheduler, f func() error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "use POST", http.StatusMethodNotAllowed)
+			return
+		}
+		done := make(chan error, 1)
+		if err := call(s, func() { done <- f() }); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		if err := <-done; err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		h, err := ServerHealth(s)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(h)
+	}
+}
+
+// remoteClient makes the requests of the remote command.
+type remoteClient struct {
+	url   string
+	token string
+	http  *http.Client
+}
+
+// do sends a request with body, when not nil, and decodes the JSON reply
+// into v, when not nil.
+func (c remoteClient) do(method, path string, body, v interface{}) error {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(c.url, "/")+path, r)
+	if err != nil {
+		return err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+const remoteUsage = `Usage: prayer remote [-server url] [-token token] [-cert file] command
+  health                         print how the server is doing
+  reload                         apply its config file again
+  restart                        compute its schedule again
+  announcements [file]           print its announcements, or replace them
+                                 with the JSON list in file, - for stdin
+  announce [-start day] [-end day] text
+                                 add an announcement`
+
+// remoteCommand manages a server over its JSON API, as kiosks are managed.
+func remoteCommand(args []string) int {
+	fs := flag.NewFlagSet("remote", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprintln(os.Stderr, remoteUsage) }
+	url := fs.String("server", "http://127.0.0.1:47415", "address of the server")
+	token := fs.String("token", os.Getenv("PRAYER_TOKEN"), "the server's Token, $PRAYER_TOKEN by default")
+	cert := fs.String("cert", "", "certificate to trust, such as the server.crt of a self-signed server")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	c := remoteClient{url: *url, token: *token, http: &http.Client{Timeout: 10 * time.Second}}
+	if *cert != "" {
+		pem, err := os.ReadFile(*cert)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "remote:", err)
+			return 1
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			fmt.Fprintf(os.Stderr, "remote: %s has no certificate\n", *cert)
+			return 1
+		}
+		c.http.Transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}
+	}
+
+	if err := runRemote(c, fs.Arg(0), fs.Args()[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "remote:", err)
+		return 1
+	}
+	return 0
+}
+
+func runRemote(c remoteClient, command string, args []string) error {
+	show := func(v interface{}) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err == nil {
+			fmt.Println(string(data))
+		}
+		return err
+	}
+
+	switch command {
+	case "health", "reload", "restart":
+		method, path := http.MethodPost, "/api/"+command
+		if command == "health" {
+			method = http.MethodGet
+		}
+		var h Health
+		if err := c.do(method, path, nil, &h); err != nil {
+			return err
+		}
+		return show(h)
+	case "announcements":
+		if len(args) == 0 {
+			var list []Announcement
+			if err := c.do(http.MethodGet, "/api/announcements", nil, &list); err != nil {
+				return err
+			}
+			return show(list)
+		}
+		var data []byte
+		var err error
+		if args[0] == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(args[0])
+		}
+		if err != nil {
+			return err
+		}
+		var list []Announcement
+		if err := json.Unmarshal(data, &list); err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		return c.do(http.MethodPut, "/api/announcements", list, nil)
+	case "announce":
+		fs := flag.NewFlagSet("announce", flag.ExitOnError)
+		start := fs.String("start", "", "first day to show it, like 2006-01-02")
+		end := fs.String("end", "", "last day to show it")
+		fs.Parse(args)
+		a := Announcement{Text: strings.Join(fs.Args(), " "), Start: *start, End: *end}
+		if err := a.Validate(); err != nil {
+			return err
+		}
+		var list []Announcement
+		if err := c.do(http.MethodGet, "/api/announcements", nil, &list); err != nil {
+			return err
+		}
+		return c.do(http.MethodPut, "/api/announcements", append(list, a), nil)
+	}
+	return fmt.Errorf("unknown command %q\n%s", command, remoteUsage)
+}
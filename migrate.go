@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// configVersion is the version of the config file written by this build.
+const configVersion = 2
+
+// migrations upgrade the settings of a config file from version i to i+1.
+var migrations = []func(m map[string]json.RawMessage){
+	// 0 to 1: TUISnooze becomes Snooze, used by every frontend.
+	func(m map[string]json.RawMessage) {
+		rename(m, "TUISnooze", "Snooze")
+	},
+	// 1 to 2: the signage Announcements get start and end days.
+	func(m map[string]json.RawMessage) {
+		var signage map[string]json.RawMessage
+		var texts []string
+		if json.Unmarshal(m["Signage"], &signage) != nil || json.Unmarshal(signage["Announcements"], &texts) != nil {
+			return
+		}
+		var list []Announcement
+		for _, t := range texts {
+			list = append(list, Announcement{Text: t})
+		}
+		signage["Announcements"], _ = json.Marshal(list)
+		m["Signage"], _ = json.Marshal(signage)
+	},
+}
+
+// rename moves setting from to to, unless to is already set.
+func rename(m map[string]json.RawMessage, from, to string) {
+	v, ok := m[from]
+	if !ok {
+		return
+	}
+	delete(m, from)
+	if _, ok := m[to]; !ok {
+		m[to] = v
+	}
+}
+
+// migrateSettings upgrades m to configVersion and removes its version,
+// returning the version it had. Files without one are version 0.
+func migrateSettings(m map[string]json.RawMessage) (int, error) {
+	version := 0
+	if v, ok := m["Version"]; ok {
+		if err := json.Unmarshal(v, &version); err != nil {
+			return 0, fmt.Errorf("setting Version: %w", err)
+		}
+		delete(m, "Version")
+	}
+	if version > configVersion {
+		return version, fmt.Errorf("config version %d is newer than this build's %d", version, configVersion)
+	}
+
+	for _, migrate := range migrations[version:] {
+		migrate(m)
+	}
+	return version, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"time"
+)
+
+// mqttNotifier publishes events with MQTT 3.1.1 at QoS 0, connecting for
+// each one.
+type mqttNotifier struct {
+	broker, topic      string
+	username, password string
+}
+
+func (n mqttNotifier) Notify(ev PrayerEvent) error {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(n.broker)
+	if err != nil {
+		return err
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "1883")
+	}
+
+	conn, err := net.DialTimeout("tcp", host, 10*time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	// CONNECT, with a clean session and the credentials.
+	var flags byte = 0x02
+	connect := mqttString("MQTT")
+	var login []byte
+	if n.username != "" {
+		flags |= 0x80
+		login = append(login, mqttString(n.username)...)
+		if n.password != "" {
+			flags |= 0x40
+			login = append(login, mqttString(n.password)...)
+		}
+	}
+	connect = append(connect, 4, flags, 0, 60)
+	connect = append(connect, mqttString(fmt.Sprintf("prayer-%d", time.Now().UnixNano()))...)
+	connect = append(connect, login...)
+	if _, err := conn.Write(mqttPacket(0x10, connect)); err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(conn)
+	connack := make([]byte, 4)
+	if _, err := io.ReadFull(r, connack); err != nil {
+		return err
+	}
+	if connack[0] != 0x20 {
+		return errors.New("mqtt: no CONNACK from the broker")
+	}
+	if connack[3] != 0 {
+		return fmt.Errorf("mqtt: connection refused, code %d", connack[3])
+	}
+
+	publish := append(mqttString(n.topic), payload...)
+	if _, err := conn.Write(mqttPacket(0x30, publish)); err != nil {
+		return err
+	}
+	_, err = conn.Write([]byte{0xe0, 0})
+	return err
+}
+
+// mqttPacket prefixes body with the fixed header of packet type header.
+func mqttPacket(header byte, body []byte) []byte {
+	p := []byte{header}
+	n := len(body)
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		p = append(p, b)
+		if n == 0 {
+			break
+		}
+	}
+	return append(p, body...)
+}
+
+// mqttString encodes s with its length.
+func mqttString(s string) []byte {
+	return append(binary.BigEndian.AppendUint16(nil, uint16(len(s))), s...)
+}
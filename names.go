@@ -0,0 +1,53 @@
+package main
+
+// How prayer names are shown: "" for the usual Fajr, Dhuhr…, "english",
+// "arabic" or "transliteration". prayerLabels overrides single names, e.g.
+// {"Fajr": "Namaz-e-Fajr"}.
+var (
+	prayerNameStyle = ""
+	prayerLabels    = map[string]string{}
+)
+
+var prayerNameStyles = map[string]map[string]string{
+	"english": {
+		"Fajr":    "Dawn",
+		"Sunrise": "Sunrise",
+		"Dhuhr":   "Noon",
+		"Asr":     "Afternoon",
+		"Maghrib": "Sunset",
+		"Isha":    "Night",
+	},
+	"arabic": {
+		"Fajr":    "الفجر",
+		"Sunrise": "الشروق",
+		"Dhuhr":   "الظهر",
+		"Asr":     "العصر",
+		"Maghrib": "المغرب",
+		"Isha":    "العشاء",
+	},
+	"transliteration": {
+		"Fajr":    "Fajr",
+		"Sunrise": "Shuruq",
+		"Dhuhr":   "Zuhr",
+		"Asr":     "Asr",
+		"Maghrib": "Maghrib",
+		"Isha":    "Isha",
+	},
+}
+
+// PrayerLabel returns how the prayer (or timing) name is displayed. Other
+// names are returned as is.
+func PrayerLabel(name string) string {
+	if l, ok := prayerLabels[name]; ok {
+		return l
+	}
+	if l, ok := prayerNameStyles[prayerNameStyle][name]; ok {
+		return l
+	}
+	return name
+}
+
+// Label is the displayed name of p.
+func (p Prayer) Label() string {
+	return PrayerLabel(p.Name)
+}
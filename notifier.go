@@ -0,0 +1,447 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// NotifierConfig is an entry of notifiers. Type is one of:
+//
+//   - "desktop", the frontend's notification
+//   - "sound", playing the alert's sound
+//   - "webhook", posting the event as JSON to URL
+//   - "mqtt", publishing the event as JSON to Topic on the broker at URL,
+//     "tcp://host:1883", with Username and Password if set
+//   - "telegram", sent by the bot with Token to ChatID
+//   - "slack" and "discord", posted to the channel's incoming webhook URL
+//   - "matrix", sent to Room on the homeserver at URL with the access Token
+//   - "ntfy", published to Topic on the server at URL, https://ntfy.sh when
+//     empty, with the access Token or Username and Password if set
+//   - "gotify", sent to the server at URL with the application Token
+//   - "gateway", a request to an SMS or Signal gateway such as Twilio or
+//     signal-cli's REST API: Method (POST when empty) to URL with Headers,
+//     Username and Password if set for basic authentication, and the Go
+//     template Body over PrayerEvent, where {{json .Text}} quotes a string
+//     for JSON and {{form .Text}} for a form
+//
+// Kinds limits it to these kinds of alert, such as "Adhan" or "Reminder",
+// and Names to these prayers or events, such as "Suhoor". Nothing is sent
+// during QuietHours, from the first clock time ("22:00") to the second
+// ("06:00").
+type NotifierConfig struct {
+	Type       string
+	URL        string            `json:",omitempty"`
+	Topic      string            `json:",omitempty"`
+	Username   string            `json:",omitempty"`
+	Password   string            `json:",omitempty"`
+	Token      string            `json:",omitempty"`
+	ChatID     string            `json:",omitempty"`
+	Room       string            `json:",omitempty"`
+	Method     string            `json:",omitempty"`
+	Headers    map[string]string `json:",omitempty"`
+	Body       string            `json:",omitempty"`
+	Kinds      []string          `json:",omitempty"`
+	Names      []string          `json:",omitempty"`
+	QuietHours [2]string         `json:",omitempty"`
+}
+
+// Quiet reports whether t is in c's QuietHours, with an error if they're
+// set but aren't both times like 15:04.
+func (c NotifierConfig) Quiet(t time.Time) (bool, error) {
+	if c.QuietHours == [2]string{} {
+		return false, nil
+	}
+	var hours [2]time.Time
+	for i, at := range c.QuietHours {
+		h, err := time.Parse("15:04", at)
+		if err != nil {
+			return false, fmt.Errorf("QuietHours %q is not a time like 15:04", at)
+		}
+		hours[i] = h
+	}
+	from, to := hours[0], hours[1]
+	clock := time.Date(0, 1, 1, t.Hour(), t.Minute(), 0, 0, time.UTC)
+	if from.After(to) {
+		return !clock.Before(from) || clock.Before(to), nil
+	}
+	return !clock.Before(from) && clock.Before(to), nil
+}
+
+// The notifiers alerts go to, in order. Removing one disables it.
+var notifiers = []NotifierConfig{
+	{Type: "desktop"},
+	{Type: "sound"},
+}
+
+// notifierTypes are the Types of NotifierConfig.
+var notifierTypes = []string{"desktop", "sound", "webhook", "mqtt", "telegram", "slack", "discord", "matrix", "ntfy", "gotify", "gateway"}
+
+// PrayerEvent is an alert as passed to a Notifier.
+type PrayerEvent struct {
+	Time    time.Time
+	Kind    string  // "Adhan", "Reminder", "Event"...
+	Name    string  // of the prayer or event
+	Title   string  // Name as shown
+	Message string  `json:",omitempty"`
+	Sound   string  `json:",omitempty"`
+	Volume  float64 `json:"-"`
+
+	id int64 // in the history
+}
+
+// Text is the event's message, or says what it is when there's none.
+func (ev PrayerEvent) Text() string {
+	if ev.Message != "" {
+		return ev.Message
+	}
+	if ev.Kind == "Adhan" {
+		return "Time for " + ev.Title
+	}
+	return ev.Title + " time"
+}
+
+// Notifier is a way of telling about an alert. Notify is called on the
+// scheduler's goroutine and must not block for long.
+type Notifier interface {
+	Notify(event PrayerEvent) error
+}
+
+// newNotifier returns the notifier of c, or nil if its Type isn't known.
+func (s *Scheduler) newNotifier(c NotifierConfig) Notifier {
+	switch c.Type {
+	case "desktop":
+		return desktopNotifier{s}
+	case "sound":
+		return soundNotifier{s}
+	case "webhook":
+		return background{webhookNotifier{c.URL}}
+	case "mqtt":
+		return background{mqttNotifier{c.URL, c.Topic, c.Username, c.Password}}
+	case "telegram":
+		return background{telegramNotifier{c.Token, c.ChatID}}
+	case "slack":
+		return background{chatNotifier{c.URL, "text", "*%s*: %s"}}
+	case "discord":
+		return background{chatNotifier{c.URL, "content", "**%s**: %s"}}
+	case "matrix":
+		return background{matrixNotifier{strings.TrimSuffix(c.URL, "/"), c.Token, c.Room}}
+	case "ntfy":
+		server := c.URL
+		if server == "" {
+			server = "https://ntfy.sh"
+		}
+		return background{ntfyNotifier{strings.TrimSuffix(server, "/"), c.Topic, c.Token, c.Username, c.Password}}
+	case "gotify":
+		return background{gotifyNotifier{strings.TrimSuffix(c.URL, "/"), c.Token}}
+	case "gateway":
+		return background{gatewayNotifier{c}}
+	}
+	return nil
+}
+
+// notifyAlert passes the alert of ev to the notifiers that take its kind.
+func (s *Scheduler) notifyAlert(ev BusEvent) {
+	a := ev.Alert
+	pe := PrayerEvent{
+		Time:    a.Time,
+		Kind:    a.Kind,
+		Name:    a.Name,
+		Title:   PrayerLabel(a.Name),
+		Message: a.Message,
+		Sound:   a.Sound,
+		Volume:  ev.Volume,
+		id:      ev.id,
+	}
+	for _, c := range notifiers {
+		if len(c.Kinds) > 0 && !contains(c.Kinds, a.Kind) || len(c.Names) > 0 && !contains(c.Names, a.Name) {
+			continue
+		}
+		quiet, err := c.Quiet(a.Time)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, c.Type+":", err)
+		}
+		if quiet {
+			continue
+		}
+		if n := s.newNotifier(c); n != nil {
+			if err := n.Notify(pe); err != nil {
+				fmt.Fprintln(os.Stderr, c.Type+":", err)
+			}
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// background runs a slow Notifier on another goroutine.
+type background struct{ Notifier }
+
+func (b background) Notify(ev PrayerEvent) error {
+	go func() {
+		if err := b.Notifier.Notify(ev); err != nil {
+			fmt.Fprintln(os.Stderr, "notify:", err)
+		}
+	}()
+	return nil
+}
+
+// desktopNotifier shows the message with the Scheduler's hooks, with a
+// way to snooze reminders.
+type desktopNotifier struct{ s *Scheduler }
+
+func (n desktopNotifier) Notify(ev PrayerEvent) error {
+	if ev.Message != "" && ev.Kind == "Reminder" && n.s.NotifyReminder != nil {
+		n.s.NotifyReminder(ev.Title, ev.Message)
+	} else if ev.Message != "" {
+		n.s.Notify(ev.Title, ev.Message)
+	}
+	return nil
+}
+
+// soundNotifier plays the sound, marking it played in the history, and
+// publishes AdhanFinished after the adhan.
+type soundNotifier struct{ s *Scheduler }
+
+func (n soundNotifier) Notify(ev PrayerEvent) error {
+	if ev.Sound == "" {
+		return nil
+	}
+	done := func() {
+		if ev.Kind == "Adhan" {
+			n.s.Bus.Publish(BusEvent{Topic: AdhanFinished})
+		}
+	}
+	if n.s.Muted {
+		go done()
+		return nil
+	}
+	soundsPlaying.Add(1)
+	go func() {
+		defer soundsPlaying.Done()
+		defer done()
+		defer n.s.Recover("sound")
+		if err := PlaySoundVolume(ev.Sound, ev.Volume); err != nil {
+			n.s.soundFailed(err)
+			return
+		}
+		db.Exec(`UPDATE alerts SET played = 1 WHERE id = ?`, ev.id)
+	}()
+	return nil
+}
+
+// notifierClient sends the requests of the notifiers, giving up on a
+// service that doesn't answer rather than holding the alerts after it.
+var notifierClient = &http.Client{Timeout: 10 * time.Second}
+
+type webhookNotifier struct{ url string }
+
+func (n webhookNotifier) Notify(ev PrayerEvent) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	resp, err := notifierClient.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", n.url, resp.Status)
+	}
+	return nil
+}
+
+type telegramNotifier struct{ token, chatID string }
+
+func (n telegramNotifier) Notify(ev PrayerEvent) error {
+	resp, err := notifierClient.PostForm("https://api.telegram.org/bot"+n.token+"/sendMessage", url.Values{
+		"chat_id": {n.chatID},
+		"text":    {ev.Title + ": " + ev.Text()},
+	})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("sending to chat %s: %s", n.chatID, resp.Status)
+	}
+	return nil
+}
+
+// chatNotifier posts to a Slack or Discord webhook, the message formatted
+// with the title and text in the field of the JSON body.
+type chatNotifier struct{ url, field, format string }
+
+func (n chatNotifier) Notify(ev PrayerEvent) error {
+	body, err := json.Marshal(map[string]string{n.field: fmt.Sprintf(n.format, ev.Title, ev.Text())})
+	if err != nil {
+		return err
+	}
+	resp, err := notifierClient.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook: %s", resp.Status)
+	}
+	return nil
+}
+
+type matrixNotifier struct{ homeserver, token, room string }
+
+func (n matrixNotifier) Notify(ev PrayerEvent) error {
+	body, err := json.Marshal(map[string]string{
+		"msgtype": "m.text",
+		"body":    ev.Title + ": " + ev.Text(),
+	})
+	if err != nil {
+		return err
+	}
+	// The transaction ID makes resending the same event harmless.
+	u := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/prayer-%d",
+		n.homeserver, url.PathEscape(n.room), ev.Time.UnixNano())
+	req, err := http.NewRequest(http.MethodPut, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+n.token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := notifierClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("sending to room %s: %s", n.room, resp.Status)
+	}
+	return nil
+}
+
+// pushPriority is the priority of push notifications of the alert kind,
+// from 1 to 5, the adhan's making the phone sound.
+func pushPriority(kind string) int {
+	switch kind {
+	case "Adhan":
+		return 5
+	case "Reminder", "Escalation":
+		return 4
+	}
+	return 3
+}
+
+type ntfyNotifier struct{ server, topic, token, username, password string }
+
+func (n ntfyNotifier) Notify(ev PrayerEvent) error {
+	req, err := http.NewRequest(http.MethodPost, n.server+"/"+url.PathEscape(n.topic), strings.NewReader(ev.Text()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Title", ev.Title)
+	req.Header.Set("Priority", fmt.Sprint(pushPriority(ev.Kind)))
+	req.Header.Set("Tags", "pray")
+	if n.token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	} else if n.username != "" {
+		req.SetBasicAuth(n.username, n.password)
+	}
+	resp, err := notifierClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("publishing to %s: %s", n.topic, resp.Status)
+	}
+	return nil
+}
+
+type gotifyNotifier struct{ server, token string }
+
+func (n gotifyNotifier) Notify(ev PrayerEvent) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"title":    ev.Title,
+		"message":  ev.Text(),
+		"priority": pushPriority(ev.Kind) * 2,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, n.server+"/message", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Gotify-Key", n.token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := notifierClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("gotify: %s", resp.Status)
+	}
+	return nil
+}
+
+// gatewayTemplate parses the Body of a gateway notifier.
+func gatewayTemplate(body string) (*template.Template, error) {
+	return template.New("body").Funcs(template.FuncMap{
+		"json": func(s string) (string, error) {
+			b, err := json.Marshal(s)
+			return string(b), err
+		},
+		"form": url.QueryEscape,
+	}).Parse(body)
+}
+
+type gatewayNotifier struct{ c NotifierConfig }
+
+func (n gatewayNotifier) Notify(ev PrayerEvent) error {
+	tmpl, err := gatewayTemplate(n.c.Body)
+	if err != nil {
+		return err
+	}
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, ev); err != nil {
+		return err
+	}
+
+	method := n.c.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequest(method, n.c.URL, &body)
+	if err != nil {
+		return err
+	}
+	for k, v := range n.c.Headers {
+		req.Header.Set(k, v)
+	}
+	if n.c.Username != "" {
+		req.SetBasicAuth(n.c.Username, n.c.Password)
+	}
+	resp, err := notifierClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("gateway: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// apiRoute is an endpoint of the JSON API. The server's handler and its
+// OpenAPI document are both made from the routes, so the document can't
+// fall behind the handlers.
+type apiRoute struct {
+	Path    string
+	Summary string
+	Method  string // POST for an action, GET when it's empty
+	Params  []apiParam
+	Result  interface{} // a value of the type of the response, nil for an event stream
+	Body    interface{} // a value of the type PUT replaces the result with, nil when it can't
+	Handler http.HandlerFunc
+}
+
+// apiParam is a query parameter of a route.
+type apiParam struct {
+	Name        string
+	Description string
+	Type        string // of the JSON schema, string when it's empty
+	Repeated    bool
+}
+
+// OpenAPI returns the OpenAPI 3 document of routes, the schemas of their
+// responses following the Go types' JSON encoding.
+func OpenAPI(routes []apiRoute) map[string]interface{} {
+	schemas := map[string]interface{}{}
+	text := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"description": description,
+			"content":     map[string]interface{}{"text/plain": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}},
+		}
+	}
+
+	paths := map[string]interface{}{}
+	for _, r := range routes {
+		var params []interface{}
+		for _, p := range r.Params {
+			schema := map[string]interface{}{"type": "string"}
+			if p.Type != "" {
+				schema["type"] = p.Type
+			}
+			if p.Repeated {
+				schema = map[string]interface{}{"type": "array", "items": schema}
+			}
+			params = append(params, map[string]interface{}{
+				"name":        p.Name,
+				"in":          "query",
+				"description": p.Description,
+				"schema":      schema,
+			})
+		}
+
+		content := map[string]interface{}{"text/event-stream": map[string]interface{}{
+			"schema": schemaOf(reflect.TypeOf(PluginMessage{}), schemas),
+		}}
+		if r.Result != nil {
+			content = map[string]interface{}{"application/json": map[string]interface{}{
+				"schema": schemaOf(reflect.TypeOf(r.Result), schemas),
+			}}
+		}
+		responses := map[string]interface{}{
+			"200": map[string]interface{}{"description": "OK", "content": content},
+			"503": text("The scheduler isn't running or the timings can't be loaded"),
+		}
+		if len(params) > 0 {
+			responses["400"] = text("A parameter isn't valid")
+		}
+		if server.RateLimit > 0 {
+			responses["429"] = text("The client made too many requests")
+		}
+		if server.Token != "" {
+			responses["401"] = text("The token is missing or wrong")
+		}
+		// Changes are refused from other machines without a Token.
+		forbidden := text("Only this machine can make changes without a Token")
+		if r.Method == http.MethodPost {
+			responses["500"] = text("The action failed, as when the config file doesn't load")
+			if server.Token == "" {
+				responses["403"] = forbidden
+			}
+		}
+
+		op := map[string]interface{}{
+			"operationId": strings.TrimPrefix(strings.ReplaceAll(r.Path, "/", "_"), "_"),
+			"summary":     r.Summary,
+			"responses":   responses,
+		}
+		if params != nil {
+			op["parameters"] = params
+		}
+		method := "get"
+		if r.Method != "" {
+			method = strings.ToLower(r.Method)
+		}
+		path := map[string]interface{}{method: op}
+		if r.Body != nil {
+			putResponses := map[string]interface{}{}
+			for code, resp := range responses {
+				putResponses[code] = resp
+			}
+			putResponses["400"] = text("The body isn't valid")
+			if server.Token == "" {
+				putResponses["403"] = forbidden
+			}
+			put := map[string]interface{}{
+				"operationId": op["operationId"].(string) + "_put",
+				"summary":     "Replace: " + r.Summary,
+				"requestBody": map[string]interface{}{
+					"required": true,
+					"content": map[string]interface{}{"application/json": map[string]interface{}{
+						"schema": schemaOf(reflect.TypeOf(r.Body), schemas),
+					}},
+				},
+				"responses": putResponses,
+			}
+			path["put"] = put
+		}
+		paths[r.Path] = path
+	}
+
+	components := map[string]interface{}{"schemas": schemas}
+	doc := map[string]interface{}{
+		"openapi":    "3.0.3",
+		"info":       map[string]interface{}{"title": "Prayer", "version": version},
+		"paths":      paths,
+		"components": components,
+	}
+	if server.Token != "" {
+		components["securitySchemes"] = map[string]interface{}{
+			"token": map[string]interface{}{"type": "http", "scheme": "bearer"},
+		}
+		doc["security"] = []interface{}{map[string]interface{}{"token": []string{}}}
+	}
+	return doc
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaOf returns the JSON schema of t, adding the structs it refers to
+// to schemas.
+func schemaOf(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Ptr:
+		return schemaOf(t.Elem(), schemas)
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaOf(t.Elem(), schemas)}
+	case t.Kind() == reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaOf(t.Elem(), schemas)}
+	case t.Kind() == reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case t.Kind() == reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case t.Kind() != reflect.Struct:
+		return map[string]interface{}{}
+	}
+
+	ref := map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	if _, ok := schemas[t.Name()]; ok {
+		return ref
+	}
+	props := map[string]interface{}{}
+	schema := map[string]interface{}{"type": "object", "properties": props}
+	schemas[t.Name()] = schema // before the fields, for the types referring to themselves
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := strings.Split(f.Tag.Get("json"), ",")
+		if tag[0] == "-" {
+			continue
+		}
+		name := f.Name
+		if tag[0] != "" {
+			name = tag[0]
+		}
+		props[name] = schemaOf(f.Type, schemas)
+		if len(tag) < 2 || tag[1] != "omitempty" {
+			required = append(required, name)
+		}
+	}
+	if required != nil {
+		schema["required"] = required
+	}
+	return ref
+}
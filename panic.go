@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"time"
+)
+
+// errorRepeat is how long the same error isn't shown again, as a panic
+// repeats every tick until its cause is fixed.
+const errorRepeat = time.Hour
+
+// panicError is a recovered panic, with the stack it happened on.
+type panicError struct {
+	where string
+	value interface{}
+	stack []byte
+}
+
+func newPanicError(where string, r interface{}) panicError {
+	return panicError{where, r, debug.Stack()}
+}
+
+func (e panicError) Error() string {
+	return fmt.Sprintf("%s: %v", e.where, e.value)
+}
+
+// logError logs err to stderr, with its stack if it's a panic.
+func logError(err error) {
+	if p, ok := err.(panicError); ok {
+		fmt.Fprintf(os.Stderr, "%v\n%s", p, p.stack)
+		return
+	}
+	fmt.Fprintln(os.Stderr, err)
+}
+
+// Recover, deferred, turns a panic, such as a failed download or a bad
+// timings file, into an error logged and shown with Notify, so that it
+// doesn't take the app down and the countdown keeps running. where names
+// what panicked. It can be deferred on any goroutine.
+func (s *Scheduler) Recover(where string) {
+	if r := recover(); r != nil {
+		s.failed(newPanicError(where, r))
+	}
+}
+
+// failed shows err on the scheduler's goroutine, from any goroutine.
+func (s *Scheduler) failed(err error) {
+	go s.Do(func() { s.showError(err) })
+}
+
+// showError logs and notifies err, unless it was within errorRepeat.
+func (s *Scheduler) showError(err error) {
+	now := time.Now()
+	if at, ok := s.errorsShown[err.Error()]; ok && now.Sub(at) < errorRepeat {
+		return
+	}
+	if s.errorsShown == nil {
+		s.errorsShown = make(map[string]time.Time)
+	}
+	s.errorsShown[err.Error()] = now
+	logError(err)
+	defer func() {
+		if r := recover(); r != nil {
+			logError(newPanicError("notify", r))
+		}
+	}()
+	s.Notify("Prayer ran into a problem", err.Error())
+}
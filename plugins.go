@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Plugins are the executables in pluginsDir. Each is started with Prayer
+// and gets every event of the scheduler's Bus as a line of JSON on its
+// standard input.
+var (
+	pluginsDir = "./plugins"
+	runPlugins = true
+)
+
+// PluginMessage is a line sent to the plugins.
+type PluginMessage struct {
+	Topic    string
+	Time     time.Time
+	Alert    *Alert    `json:",omitempty"`
+	Text     string    `json:",omitempty"`
+	Location *Location `json:",omitempty"`
+	Next     Prayer    // the upcoming prayer
+}
+
+// StartPlugins starts the plugins and sends them the events of s. A plugin
+// that's too slow to read misses events rather than holding up alerts.
+func StartPlugins(s *Scheduler) {
+	if !runPlugins {
+		return
+	}
+	entries, err := os.ReadDir(pluginsDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Fprintln(os.Stderr, "plugins:", err)
+		}
+		return
+	}
+
+	var lines []chan []byte
+	for _, e := range entries {
+		path := filepath.Join(pluginsDir, e.Name())
+		if !isExecutable(path) {
+			continue
+		}
+		in, err := startPlugin(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "plugins:", err)
+			continue
+		}
+		ch := make(chan []byte, 64)
+		lines = append(lines, ch)
+		go func() {
+			defer in.Close()
+			for line := range ch {
+				if _, err := in.Write(line); err != nil {
+					fmt.Fprintf(os.Stderr, "plugins: %s: %v\n", path, err)
+					return
+				}
+			}
+		}()
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	send := func(ev BusEvent) {
+		line, err := json.Marshal(pluginMessage(s, ev))
+		if err != nil {
+			panic(err)
+		}
+		line = append(line, '\n')
+		for _, ch := range lines {
+			select {
+			case ch <- line:
+			default:
+			}
+		}
+	}
+	for _, topic := range busTopics {
+		s.Bus.Subscribe(topic, send)
+	}
+}
+
+// pluginMessage returns the message of ev, published on s.
+func pluginMessage(s *Scheduler, ev BusEvent) PluginMessage {
+	m := PluginMessage{Topic: ev.Topic, Time: time.Now(), Text: ev.Text, Next: s.Next}
+	if ev.Alert.Kind != "" || ev.Alert.Name != "" {
+		m.Alert = &ev.Alert
+	}
+	if ev.Topic == LocationChanged {
+		m.Location = &ev.Location
+	}
+	return m
+}
+
+// startPlugin runs the plugin at path, its output going to Prayer's.
+func startPlugin(path string) (io.WriteCloser, error) {
+	cmd := exec.Command(path)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	go func() {
+		if err := cmd.Wait(); err != nil {
+			fmt.Fprintf(os.Stderr, "plugins: %s: %v\n", path, err)
+		}
+	}()
+	return in, nil
+}
+
+// isExecutable reports whether path is a file that can be run.
+func isExecutable(path string) bool {
+	fi, err := os.Stat(path)
+	if err != nil || fi.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(path))
+		return ext == ".exe" || ext == ".bat" || ext == ".cmd"
+	}
+	return fi.Mode()&0111 != 0
+}
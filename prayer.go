@@ -1,42 +1,166 @@
 package main
 
 import (
-	"encoding/json"
+	"flag"
 	"fmt"
-	"image/png"
-	"io"
-	"net/http"
 	"os"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+	"github.com/faiface/beep/generators"
 	"github.com/faiface/beep/speaker"
 	"github.com/faiface/beep/wav"
-	"github.com/gen2brain/iup-go/iup"
 )
 
 var (
-	timingsDir   = "./"
-	remindBefore = 5 * time.Minute
-	latitude     = 30.983334
-	longitude    = 41.016666
+	timingsDir     = "./"
+	adhanSound     = "adhan.wav"
+	startMinimized = false
 )
 
-const (
-	apiUrl = "https://api.aladhan.com/v1/calendar"
+// Location profiles, the first one is active on startup until another one
+// is switched to.
+var profiles = []Location{
+	{Name: "Arar", Latitude: 30.983334, Longitude: 41.016666},
+}
+
+var location = profiles[0]
+
+// Cities shown side by side in the cities dashboard.
+var cities = []Location{
+	{Name: "Makkah", Latitude: 21.422487, Longitude: 39.826206},
+	{Name: "Madinah", Latitude: 24.470901, Longitude: 39.612236},
+}
+
+// Reminders fired before every prayer, unless the prayer has its own
+// list in prayerReminders.
+var defaultReminders = []Reminder{
+	{Before: Duration(5 * time.Minute), Sound: "tasbih.wav"},
+}
+
+var prayerReminders = map[string][]Reminder{
+	// "Fajr": {
+	// 	{Before: Duration(30 * time.Minute), Notify: true},
+	// 	{Before: Duration(10 * time.Minute), Sound: "tasbih.wav", Notify: true},
+	// },
+}
+
+const apiUrl = "https://api.aladhan.com/v1/calendar"
+
+var (
 	method = 4
 	school = 0
 )
 
+// Methods of the API following the Shia rules, with Maghrib delayed after
+// sunset and midnight halfway from sunset to Fajr.
+const (
+	methodJafari       = 0
+	methodTehran       = 7
+	methodMoonsighting = 15
+)
+
+// shafaq is the twilight Isha follows with the Moonsighting Committee
+// method: "general", "ahmer" (the red one, earlier) or "abyad" (the white
+// one, later).
+var shafaq = "general"
+
+// Timings shown below the prayers, e.g. "Sunset" and "Midnight" with the
+// Jafari method, where they differ from Maghrib and the end of Isha.
+var extraTimings = []string{}
+
+// How Midnight and the thirds of the night are found: "standard" (halfway
+// from sunset to sunrise), "jafari" (sunset to Fajr) or "" for the method's,
+// jafari with the Shia ones.
+var midnightMode = ""
+
+// jafariMidnight reports whether midnightMode is jafari with method m.
+func jafariMidnight(m int) bool {
+	if midnightMode == "" {
+		return m == methodJafari || m == methodTehran
+	}
+	return midnightMode == "jafari"
+}
+
+// calcKey tells apart the timings files of loc with different calculation
+// settings.
+func calcKey(loc Location) string {
+	m := loc.CalcMethod()
+	key := fmt.Sprint(m)
+	if jafariMidnight(m) {
+		key += "j"
+	}
+	if m == methodMoonsighting {
+		key += "-" + shafaq
+	}
+	if school != 0 {
+		key += fmt.Sprintf("-s%d", school)
+	}
+	if offlineTimings {
+		key += "-offline-" + rounding
+		if appliedZone != "" {
+			key += "-" + strings.ReplaceAll(appliedZone, "/", "_")
+		}
+		if loc.Elevation != 0 {
+			key += fmt.Sprintf("-%vm", loc.Elevation)
+		}
+	}
+	return key
+}
+
+// Location is a place to pray at. Elevation, in meters above the
+// surroundings, only moves sunrise and sunset with offlineTimings, the API
+// having no such setting.
+type Location struct {
+	Name      string
+	Latitude  float64
+	Longitude float64
+	Elevation float64 `json:",omitempty"`
+	// Method replaces the calculation method while the location is active.
+	Method *int `json:",omitempty"`
+}
+
+// CalcMethod returns the calculation method used at l.
+func (l Location) CalcMethod() int {
+	if l.Method != nil {
+		return *l.Method
+	}
+	return method
+}
+
+// Is reports whether l and o are the same place, whatever their methods.
+func (l Location) Is(o Location) bool {
+	return l.Name == o.Name && l.Latitude == o.Latitude && l.Longitude == o.Longitude
+}
+
+var prayerNames = []string{"Fajr", "Dhuhr", "Asr", "Maghrib", "Isha"}
+
 type Prayer struct {
 	Name string
 	Time time.Time
 }
 
 func (p Prayer) String() string {
-	return fmt.Sprintf("%-7s %s", p.Name, p.Time.Format("03:04"))
+	return fmt.Sprintf("%-7s %s", p.Label(), p.Time.Format(timeLayout("03:04")))
+}
+
+// Reminder is an alert fired Before a prayer. Sound is a wav file to play
+// (empty for none) and Notify shows a tray balloon.
+type Reminder struct {
+	Before Duration
+	Sound  string
+	Notify bool
+}
+
+func RemindersFor(name string) []Reminder {
+	if r, ok := prayerReminders[name]; ok {
+		return r
+	}
+	return defaultReminders
 }
 
 // --------------------------------------------------
@@ -65,57 +189,102 @@ func (prayers Prayers) Swap(i, j int) {
 
 // --------------------------------------------------
 
-func DownloadTimings(t time.Time) string {
+// DownloadTimings returns the file of the month of t's timings at loc,
+// downloading or computing it first when it's not cached yet. It panics if
+// it can't.
+func DownloadTimings(loc Location, t time.Time) string {
+	timingsPath, err := cacheTimings(loc, t)
+	if err != nil {
+		panic(err)
+	}
+	return timingsPath
+}
+
+// cacheTimings is DownloadTimings returning the error. The month is kept
+// compressed, and also found uncompressed or in a file named after t's day,
+// as they were kept before.
+func cacheTimings(loc Location, t time.Time) (string, error) {
 	year, month, _ := t.Date()
-	timingsPath := fmt.Sprintf("%vtimings-%v.json", timingsDir, t.Format(time.DateOnly))
-	if _, err := os.Stat(timingsPath); os.IsNotExist(err) {
-		requestUrl := fmt.Sprintf("%v/%v/%v?latitude=%v&longitude=%v&method=%v",
-			apiUrl, year, int(month), latitude, longitude, method)
-
-		fmt.Println("Downloading timings...")
-		resp, err := http.Get(requestUrl)
-		if err != nil {
-			panic(err)
+	name := func(day time.Time, ext string) string {
+		return fmt.Sprintf("%vtimings-%v-%v,%v-%v%v",
+			timingsDir, day.Format(time.DateOnly), loc.Latitude, loc.Longitude, calcKey(loc), ext)
+	}
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
+	timingsPath := name(first, ".json.gz")
+	for _, path := range []string{timingsPath, name(first, ".json"), name(t, ".json")} {
+		if info, err := os.Stat(path); err == nil {
+			markUsed(path, info)
+			return path, nil
 		}
+	}
+	if offlineTimings {
+		return timingsPath, WriteCalendar(timingsPath, loc, t)
+	}
 
-		f, err := os.Create(timingsPath)
-		defer f.Close()
-		if err != nil {
-			panic(err)
-		}
+	m := loc.CalcMethod()
+	mode := 0
+	if jafariMidnight(m) {
+		mode = 1
+	}
+	requestUrl := fmt.Sprintf("%v/%v/%v?latitude=%v&longitude=%v&method=%v&school=%v&midnightMode=%v",
+		apiUrl, year, int(month), loc.Latitude, loc.Longitude, m, school, mode)
+	if m == methodMoonsighting {
+		requestUrl += "&shafaq=" + shafaq
+	}
 
-		io.Copy(f, resp.Body)
+	fmt.Println("Downloading timings...")
+	data, err := fetchTimings(requestUrl)
+	if err != nil {
+		return "", err
 	}
-	return timingsPath
+	return timingsPath, writeTimings(timingsPath, data)
 }
 
-func FilterPrayers(pm map[string]interface{}) map[string]string {
+func FilterPrayers(pm map[string]string) map[string]string {
 	m := make(map[string]string, len(pm))
 
 	for k, v := range pm {
 		if k == "Fajr" || k == "Dhuhr" || k == "Asr" || k == "Maghrib" || k == "Isha" {
-			// p := v.(string)
-			// m[k] = p[:5]		// remove timezone suffix
-			m[k] = v.(string)
+			// m[k] = v[:5]		// remove timezone suffix
+			m[k] = v
 		}
 	}
 	return m
 }
 
-func MapToPrayers(m map[string]string, t time.Time) Prayers {
+// ParseTiming parses an API timing like "05:12 (+03)", in any of the styles
+// of splitTiming, on t's day. With a zone, the clock time is taken in it
+// and the offset ignored, so the timings after a DST change on the day are
+// right. Without, it's taken at its offset, or in the local zone when it
+// has none. It's shown in the TimeZone when set.
+func ParseTiming(v string, t time.Time, zone *time.Location) time.Time {
+	c, offset, ok, err := splitTiming(v)
+	if err != nil {
+		panic(err)
+	}
+	at := func(zone *time.Location) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), c.Hour(), c.Minute(), c.Second(), 0, zone)
+	}
+	var parsed time.Time
+	if zone != nil {
+		parsed = at(zone)
+	} else if parsed = at(time.Local); ok {
+		if _, local := parsed.Zone(); local != offset {
+			parsed = at(time.FixedZone("", offset))
+		}
+	}
+	if appliedZone != "" {
+		parsed = parsed.In(time.Local)
+	}
+	return parsed
+}
+
+func MapToPrayers(m map[string]string, t time.Time, zone *time.Location) Prayers {
 	prayers := make(Prayers, 5)
 
 	i := 0
 	for k, v := range m {
-		parsed, err := time.Parse("15:04 (-07)", v)
-		if err != nil {
-			panic(err)
-		}
-
-		// -1 because day and month default to 1
-		parsed = parsed.AddDate(t.Year(), int(t.Month())-1, t.Day()-1)
-
-		prayers[i] = Prayer{Name: k, Time: parsed}
+		prayers[i] = Prayer{Name: k, Time: ParseTiming(v, t, zone)}
 		i++
 	}
 
@@ -123,47 +292,131 @@ func MapToPrayers(m map[string]string, t time.Time) Prayers {
 	return prayers
 }
 
-func PrayerTimings(t time.Time) Prayers {
-	timingsPath := DownloadTimings(t)
+// DayData returns t's entry in the month calendar of loc. It panics with
+// what's wrong with the calendar if it can't be read, removing it.
+func DayData(loc Location, t time.Time) apiDay {
+	timingsPath := DownloadTimings(loc, t)
 	today := t.Day()
 
-	f, err := os.Open(timingsPath)
-	defer f.Close()
+	data, err := readTimings(timingsPath)
 	if err != nil {
 		panic(err)
 	}
 
-	data, err := io.ReadAll(f)
+	days, err := DecodeMonth(data, offlineTimings)
+	if err == nil && len(days) < today {
+		err = fmt.Errorf("missing data[%d]", today-1)
+	}
 	if err != nil {
-		panic(err)
+		os.Remove(timingsPath)
+		panic(fmt.Errorf("%s: %w, removed to get it again", timingsPath, err))
 	}
 
-	var jsonMap struct{ Data []interface{} }
+	return days[today-1]
+}
 
-	err = json.Unmarshal(data, &jsonMap)
+// DayZone returns the time zone of the location of a day entry, nil when
+// it has none or it's unknown here.
+func DayZone(day apiDay) *time.Location {
+	name := day.Meta.Timezone
+	if name == "" {
+		return nil
+	}
+	zone, err := time.LoadLocation(name)
 	if err != nil {
-		panic(err)
+		return nil
 	}
+	return zone
+}
 
-	todayData := jsonMap.Data[today-1].(map[string]interface{})
-	timings := FilterPrayers(todayData["timings"].(map[string]interface{}))
+func PrayerTimings(loc Location, t time.Time) Prayers {
+	todayData := DayData(loc, t)
+	timings := FilterPrayers(todayData.Timings)
 
-	return MapToPrayers(timings, t)
+	return MapToPrayers(timings, t, DayZone(todayData))
 }
 
-func FormatNextPrayer(p Prayer) string {
-	rem := p.Time.Sub(time.Now())
+// DayTimings returns every timing of t's day by name, including Sunrise,
+// Sunset, Imsak and Midnight.
+func DayTimings(loc Location, t time.Time) map[string]time.Time {
+	data := DayData(loc, t)
+	zone := DayZone(data)
+
+	m := make(map[string]time.Time, len(data.Timings))
+	for k, v := range data.Timings {
+		m[k] = ParseTiming(v, t, zone)
+	}
+	return m
+}
+
+type HijriDate struct {
+	Day, Month, Year int
+	MonthName        string
+}
 
+func Hijri(loc Location, t time.Time) HijriDate {
+	hijri := DayData(loc, t).Date.Hijri
+
+	var h HijriDate
+	h.Day, _ = apiNumber(string(hijri.Day))
+	h.Year, _ = apiNumber(string(hijri.Year))
+	h.Month, _ = apiNumber(string(hijri.Month.Number))
+	h.MonthName = hijri.Month.En
+	return h
+}
+
+// How countdowns are shown: "clock" (01:23:45), "minutes" (01:23),
+// "words" (1h 23m) or "time", the clock time they end at.
+var countdownFormat = "clock"
+
+// FormatRemaining formats rem as countdownFormat, "time" falling back to
+// "minutes".
+func FormatRemaining(rem time.Duration) string {
 	h := rem / time.Hour
 	rem -= h * time.Hour
 	m := rem / time.Minute
 	rem -= m * time.Minute
 	s := rem / time.Second
 
-	return fmt.Sprintf("Next prayer is %s\nafter %02d:%02d:%02d", p.Name, h, m, s)
+	switch countdownFormat {
+	case "minutes", "time":
+		return fmt.Sprintf("%02d:%02d", h, m)
+	case "words":
+		if h == 0 {
+			return fmt.Sprintf("%dm", m)
+		}
+		return fmt.Sprintf("%dh %dm", h, m)
+	}
+	return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+}
+
+// FormatUntil formats the time left until t after word ("after", "in"), or
+// t itself when countdownFormat is "time".
+func FormatUntil(word string, t time.Time) string {
+	if countdownFormat == "time" {
+		return "at " + t.Format(timeLayout("03:04"))
+	}
+	return word + " " + FormatRemaining(time.Until(t))
 }
 
-func NextPrayer(prayers Prayers) (Prayer, bool) {
+func FormatNextPrayer(p Prayer) string {
+	return fmt.Sprintf("Next prayer is %s\n%s", p.Label(), FormatUntil("after", p.Time))
+}
+
+// Show the countdown to the next prayer in the window title, and so on the
+// taskbar button.
+var countdownInTitle = true
+
+// FormatTitle is the window title, counting down to p when
+// countdownInTitle is set.
+func FormatTitle(p Prayer, loc Location) string {
+	if !countdownInTitle {
+		return "Prayer times in " + loc.Name
+	}
+	return fmt.Sprintf("%s %s - Prayer times in %s", p.Label(), FormatUntil("in", p.Time), loc.Name)
+}
+
+func NextPrayer(loc Location, prayers Prayers) (Prayer, bool) {
 	timingsChanged := false
 	for _, v := range prayers {
 		if time.Now().Before(v.Time) {
@@ -172,7 +425,7 @@ func NextPrayer(prayers Prayers) (Prayer, bool) {
 	}
 
 	nextDay := time.Now().AddDate(0, 0, 1)
-	newPrayerTimings := PrayerTimings(nextDay)
+	newPrayerTimings := PrayerTimings(loc, nextDay)
 
 	copy(prayers, newPrayerTimings)
 
@@ -181,137 +434,121 @@ func NextPrayer(prayers Prayers) (Prayer, bool) {
 }
 
 // --------------------------------------------------
-// Gui
-
-func guiMain(prayers Prayers) int {
-	iup.Open()
-	defer iup.Close()
-
-	iup.SetGlobal("DEFAULTFONT", "Courier 15")
-
-	list := iup.List()
-	updateTimings := func() {
-		for i, p := range prayers {
-			iup.SetAttribute(list, fmt.Sprint(i+1), fmt.Sprint(p))
-		}
-	}
-	updateTimings()
-
-	listFrame := iup.Frame(list)
-	iup.SetAttribute(listFrame, "TITLE", "Prayers times")
-
-	np, _ := NextPrayer(prayers)
-	nextPrayer := iup.Label(FormatNextPrayer(np))
-
-	iup.SetAttribute(nextPrayer, "ALIGNMENT", "ACENTER:ACENTER")
-	iup.SetAttribute(nextPrayer, "EXPAND", "YES")
-	nextPrayerFrame := iup.Frame(nextPrayer)
-	iup.SetAttribute(nextPrayerFrame, "TITLE", "Next Prayer")
+// Sound
 
-	hbox := iup.Hbox(listFrame, nextPrayerFrame)
-	iup.SetAttribute(hbox, "ALIGNMENT", "ACENTER")
+var (
+	soundMu   sync.Mutex
+	soundStop = make(chan bool) // closed by StopSound
+)
 
-	timer := iup.Timer()
-	iup.SetAttribute(timer, "TIME", 1000) // 1000ms -> 1s
-	iup.SetCallback(timer, "ACTION_CB", iup.TimerActionFunc(func(ih iup.Ihandle) int {
-		np, timingsChanged := NextPrayer(prayers)
-		if timingsChanged {
-			updateTimings()
-		}
+// StopSound stops the sounds playing, returning early from PlaySound and
+// PlayBeep.
+func StopSound() {
+	soundMu.Lock()
+	close(soundStop)
+	soundStop = make(chan bool)
+	soundMu.Unlock()
+}
 
-		rem := np.Time.Sub(time.Now()).Round(time.Second)
-		if rem == remindBefore {
-			go PlaySound("tasbih.wav")
-		}
+// play plays s until it ends or StopSound is called.
+func play(s beep.Streamer) {
+	soundMu.Lock()
+	stop := soundStop
+	soundMu.Unlock()
 
-		if rem == time.Second {
-			go PlaySound("adhan.wav")
-		}
+	done := make(chan bool, 1)
+	speaker.Play(beep.Seq(s, beep.Callback(func() {
+		done <- true
+	})))
+	select {
+	case <-done:
+	case <-stop:
+		speaker.Clear()
+	}
+}
 
-		iup.SetAttribute(nextPrayer, "TITLE", FormatNextPrayer(np))
-		return iup.DEFAULT
-	}))
-	iup.SetAttribute(timer, "RUN", "YES")
+func PlaySound(wavPath string) error {
+	return PlaySoundVolume(wavPath, 0)
+}
 
-	// tray icon
-	file, err := os.Open("icon.png")
+// PlaySoundVolume plays wavPath with its volume changed by volume powers of
+// two, -1 being half as loud. It returns an error if the file can't be
+// decoded or there's no audio device.
+func PlaySoundVolume(wavPath string, volume float64) error {
+	streamer, err := openSound(wavPath)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	icon, err := png.Decode(file)
-	iup.ImageFromImage(icon).SetHandle("icon")
-
-	closeButton := iup.Button("Close")
-	iup.SetAttribute(closeButton, "PADDING", "5x5")
-	iup.SetCallback(closeButton, "ACTION", iup.ActionFunc(func(ih iup.Ihandle) int {
-		return iup.CLOSE
-	}))
-
-	vbox := iup.Vbox(hbox, closeButton)
-	vbox.SetAttributes(map[string]string{
-		"ALIGNMENT": "ACENTER",
-		"MARGIN":    "2x2",
-	})
-
-	dlg := iup.Dialog(vbox)
-	dlg.SetAttributes(map[string]string{
-		"TITLE":     "Prayer times in Arar",
-		"TRAY":      "YES",
-		"TRAYIMAGE": "icon",
-		"TOPMOST":   "YES",
-	})
-
-	iup.SetCallback(dlg, "CLOSE_CB", iup.CloseFunc(func(ih iup.Ihandle) int {
-		iup.SetAttribute(ih, "HIDETASKBAR", "YES")
-		return iup.IGNORE
-	}))
-
-	iup.SetCallback(dlg, "TRAYCLICK_CB",
-		iup.TrayClickFunc(func(ih iup.Ihandle, but, pressed, dclick int) int {
-			if pressed == 1 {
-				switch but {
-				case 1:
-					iup.SetAttribute(ih, "HIDETASKBAR", "NO")
-				case 3:
-					iup.SetAttribute(ih, "HIDETASKBAR", "YES")
-				}
-			}
-			return iup.DEFAULT
-		}))
-
-	iup.Show(dlg)
-
-	return iup.MainLoop()
+	defer streamer.Close()
+	play(&effects.Volume{Streamer: streamer, Base: 2, Volume: volume})
+	return nil
 }
 
-// --------------------------------------------------
-// Sound
-func PlaySound(wavPath string) {
+// openSound decodes wavPath and opens the speaker at its sample rate.
+func openSound(wavPath string) (beep.StreamSeekCloser, error) {
 	f, err := os.Open(wavPath)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-
 	streamer, format, err := wav.Decode(f)
 	if err != nil {
-		panic(err)
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", wavPath, err)
 	}
-	defer streamer.Close()
-
-	speaker.Init(format.SampleRate, format.SampleRate.N(time.Second/10))
+	if err := speaker.Init(format.SampleRate, format.SampleRate.N(time.Second/10)); err != nil {
+		streamer.Close()
+		return nil, err
+	}
+	return streamer, nil
+}
 
-	done := make(chan bool)
-	speaker.Play(beep.Seq(streamer, beep.Callback(func() {
-		done <- true
-	})))
-	<-done
+// PlayBeep plays a short tone lasting d.
+func PlayBeep(d time.Duration) error {
+	sr := beep.SampleRate(44100)
+	tone, err := generators.SinTone(sr, 880)
+	if err != nil {
+		return err
+	}
+	if err := speaker.Init(sr, sr.N(time.Second/10)); err != nil {
+		return err
+	}
+	play(beep.Take(sr.N(d), tone))
+	return nil
 }
 
 // --------------------------------------------------
 
 func main() {
-	now := time.Now()
-	prayers := PrayerTimings(now)
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
+	}
+
+	dir := flag.String("dir", "", "directory with the sounds, icon and timings")
+	flag.BoolVar(&startMinimized, "minimized", startMinimized, "start hidden in the tray")
+	flag.Parse()
+
+	if *dir != "" {
+		if err := os.Chdir(*dir); err != nil {
+			panic(err)
+		}
+	}
+
+	raise, ok := SingleInstance()
+	if !ok {
+		return
+	}
+
+	if err := LoadConfig(); err != nil {
+		fmt.Fprintln(os.Stderr, "config:", err)
+		os.Exit(1)
+	}
+	if firstRun() {
+		setupWizard()
+	}
+	OpenLog()
+	RestoreProfile()
 
-	guiMain(prayers)
+	sched := NewScheduler()
+	StartIntegrations(sched)
+	guiMain(sched, raise)
 }
@@ -0,0 +1,225 @@
+// The gRPC API of Prayer's server mode, the same as its JSON endpoints.
+// Regenerate the Go code with protoc-gen-go and protoc-gen-go-grpc:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//		--go-grpc_out=. --go-grpc_opt=paths=source_relative prayer.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: prayer.proto
+
+package prayerpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Prayer_GetStatus_FullMethodName  = "/prayer.v1.Prayer/GetStatus"
+	Prayer_GetTimings_FullMethodName = "/prayer.v1.Prayer/GetTimings"
+	Prayer_Subscribe_FullMethodName  = "/prayer.v1.Prayer/Subscribe"
+)
+
+// PrayerClient is the client API for Prayer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PrayerClient interface {
+	// GetStatus returns the schedule now, as GET /api/status.
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error)
+	// GetTimings returns the prayers of a day, at the active location or a
+	// given place, as GET /api/timings.
+	GetTimings(ctx context.Context, in *GetTimingsRequest, opts ...grpc.CallOption) (*Timings, error)
+	// Subscribe streams the scheduler's events, as GET /api/events.
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Prayer_SubscribeClient, error)
+}
+
+type prayerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPrayerClient(cc grpc.ClientConnInterface) PrayerClient {
+	return &prayerClient{cc}
+}
+
+func (c *prayerClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := c.cc.Invoke(ctx, Prayer_GetStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *prayerClient) GetTimings(ctx context.Context, in *GetTimingsRequest, opts ...grpc.CallOption) (*Timings, error) {
+	out := new(Timings)
+	err := c.cc.Invoke(ctx, Prayer_GetTimings_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *prayerClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Prayer_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Prayer_ServiceDesc.Streams[0], Prayer_Subscribe_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &prayerSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Prayer_SubscribeClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type prayerSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *prayerSubscribeClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PrayerServer is the server API for Prayer service.
+// All implementations must embed UnimplementedPrayerServer
+// for forward compatibility
+type PrayerServer interface {
+	// GetStatus returns the schedule now, as GET /api/status.
+	GetStatus(context.Context, *GetStatusRequest) (*Status, error)
+	// GetTimings returns the prayers of a day, at the active location or a
+	// given place, as GET /api/timings.
+	GetTimings(context.Context, *GetTimingsRequest) (*Timings, error)
+	// Subscribe streams the scheduler's events, as GET /api/events.
+	Subscribe(*SubscribeRequest, Prayer_SubscribeServer) error
+	mustEmbedUnimplementedPrayerServer()
+}
+
+// UnimplementedPrayerServer must be embedded to have forward compatible implementations.
+type UnimplementedPrayerServer struct {
+}
+
+func (UnimplementedPrayerServer) GetStatus(context.Context, *GetStatusRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedPrayerServer) GetTimings(context.Context, *GetTimingsRequest) (*Timings, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTimings not implemented")
+}
+func (UnimplementedPrayerServer) Subscribe(*SubscribeRequest, Prayer_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedPrayerServer) mustEmbedUnimplementedPrayerServer() {}
+
+// UnsafePrayerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PrayerServer will
+// result in compilation errors.
+type UnsafePrayerServer interface {
+	mustEmbedUnimplementedPrayerServer()
+}
+
+func RegisterPrayerServer(s grpc.ServiceRegistrar, srv PrayerServer) {
+	s.RegisterService(&Prayer_ServiceDesc, srv)
+}
+
+func _Prayer_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PrayerServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Prayer_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PrayerServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Prayer_GetTimings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTimingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PrayerServer).GetTimings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Prayer_GetTimings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PrayerServer).GetTimings(ctx, req.(*GetTimingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Prayer_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PrayerServer).Subscribe(m, &prayerSubscribeServer{stream})
+}
+
+type Prayer_SubscribeServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type prayerSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *prayerSubscribeServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Prayer_ServiceDesc is the grpc.ServiceDesc for Prayer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Prayer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "prayer.v1.Prayer",
+	HandlerType: (*PrayerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStatus",
+			Handler:    _Prayer_GetStatus_Handler,
+		},
+		{
+			MethodName: "GetTimings",
+			Handler:    _Prayer_GetTimings_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Prayer_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "prayer.proto",
+}
@@ -0,0 +1,42 @@
+package praytime
+
+import (
+	"math"
+	"time"
+)
+
+// HijriMonthNames are the names of the Hijri months, Muharram first.
+var HijriMonthNames = []string{
+	"Muḥarram", "Ṣafar", "Rabīʿ al-awwal", "Rabīʿ al-thānī", "Jumādá al-ūlá", "Jumādá al-ākhirah",
+	"Rajab", "Shaʿbān", "Ramaḍān", "Shawwāl", "Dhū al-Qaʿdah", "Dhū al-Ḥijjah",
+}
+
+// Hijri is a date in the Hijri calendar, Month counting from 1.
+type Hijri struct {
+	Day, Month, Year int
+}
+
+// MonthName returns the name of h's month.
+func (h Hijri) MonthName() string {
+	return HijriMonthNames[h.Month-1]
+}
+
+// HijriDate returns the date of day in the arithmetical Hijri calendar,
+// which can be a day apart from one following the sighting of the moon.
+func HijriDate(day time.Time) Hijri {
+	y, m, d := day.Date()
+	return tabularHijri(julianDay(y, int(m), d))
+}
+
+// tabularHijri converts Julian day jd to the arithmetical Hijri calendar.
+func tabularHijri(jd float64) Hijri {
+	l := int(math.Floor(jd+0.5)) - 1948440 + 10632
+	n := (l - 1) / 10631
+	l = l - 10631*n + 354
+	j := ((10985-l)/5316)*((50*l)/17719) + (l/5670)*((43*l)/15238)
+	l = l - ((30-j)/15)*((17719*j)/50) - (j/16)*((15238*j)/43) + 29
+	m := (24 * l) / 709
+	d := l - (709*m)/24
+	y := 30*n + j - 30
+	return Hijri{Day: d, Month: m, Year: y}
+}
@@ -0,0 +1,353 @@
+// Package praytime computes the prayer times of a day from the position of
+// the sun, following the PrayTimes.org algorithm, and the date in the tabular
+// Hijri calendar. It has no dependencies beyond the standard library, so
+// other programs can use it without the GUI, audio or network parts of
+// Prayer:
+//
+//	s := praytime.Compute(praytime.Methods[3], praytime.Coordinates{Latitude: 51.5, Longitude: -0.13}, time.Now(), praytime.Options{})
+//	if next, ok := s.Next(time.Now()); ok {
+//		fmt.Println(next.Name, next.Time.Format("15:04"))
+//	}
+//
+// It's a package of Prayer's module rather than a module of its own, so it
+// has no versions to depend on: other programs copy it, and its exported
+// names may change with Prayer.
+package praytime
+
+import (
+	"math"
+	"time"
+)
+
+// Method has the name of a calculation method and its angles below the
+// horizon, in degrees. Isha is IshaMinutes after Maghrib when it's set, and
+// Maghrib MaghribMinutes after sunset when Maghrib is 0. RamadanIshaMinutes,
+// when set, replaces IshaMinutes in Ramadan. Moonsighting methods limit Fajr
+// and Isha by the season instead of a part of the night.
+type Method struct {
+	Name               string
+	Fajr, Isha         float64
+	IshaMinutes        float64
+	RamadanIshaMinutes float64
+	Maghrib            float64
+	MaghribMinutes     float64
+	Moonsighting       bool
+}
+
+// Methods are the methods by the numbers of the Aladhan API.
+var Methods = map[int]Method{
+	0:  {Name: "Shia Ithna-Ashari, Qum", Fajr: 16, Isha: 14, Maghrib: 4},
+	1:  {Name: "Karachi", Fajr: 18, Isha: 18},
+	2:  {Name: "ISNA", Fajr: 15, Isha: 15},
+	3:  {Name: "Muslim World League", Fajr: 18, Isha: 17},
+	4:  {Name: "Umm al-Qura, Makkah", Fajr: 18.5, IshaMinutes: 90, RamadanIshaMinutes: 120},
+	5:  {Name: "Egypt", Fajr: 19.5, Isha: 17.5},
+	7:  {Name: "Tehran", Fajr: 17.7, Isha: 14, Maghrib: 4.5},
+	8:  {Name: "Gulf region", Fajr: 19.5, IshaMinutes: 90},
+	9:  {Name: "Kuwait", Fajr: 18, Isha: 17.5},
+	10: {Name: "Qatar", Fajr: 18, IshaMinutes: 90},
+	11: {Name: "Singapore", Fajr: 20, Isha: 18},
+	12: {Name: "France", Fajr: 12, Isha: 12},
+	13: {Name: "Turkey", Fajr: 18, Isha: 17},
+	14: {Name: "Russia", Fajr: 16, Isha: 15},
+	15: {Name: "Moonsighting Committee", Fajr: 18, Isha: 18, Moonsighting: true},
+	16: {Name: "Dubai", Fajr: 18.2, Isha: 18.2},
+	17: {Name: "Malaysia", Fajr: 20, Isha: 18},
+	18: {Name: "Tunisia", Fajr: 18, Isha: 18},
+	19: {Name: "Algeria", Fajr: 18, Isha: 17},
+	20: {Name: "Indonesia", Fajr: 20, Isha: 18},
+	21: {Name: "Morocco", Fajr: 19, Isha: 17},
+	22: {Name: "Portugal", Fajr: 18, IshaMinutes: 77},
+	23: {Name: "Jordan", Fajr: 18, Isha: 18, MaghribMinutes: 5},
+}
+
+// Coordinates are where the times are computed, in degrees, and the
+// elevation in meters.
+type Coordinates struct {
+	Latitude, Longitude float64
+	Elevation           float64
+}
+
+// Options are the choices beyond the method.
+type Options struct {
+	// School is 0 (Shafi) for Asr when shadows are their length more than
+	// at noon, or 1 (Hanafi) for twice their length.
+	School int
+	// Shafaq is the twilight Moonsighting methods end Isha with: "general",
+	// "ahmer" (red) or "abyad" (white). Empty is "general".
+	Shafaq string
+	// JafariMidnight ends the night at Fajr rather than sunrise.
+	JafariMidnight bool
+}
+
+// Names are the timings of a Schedule in order of the day.
+var Names = []string{
+	"Imsak", "Fajr", "Sunrise", "Dhuhr", "Asr", "Sunset", "Maghrib", "Isha",
+	"Midnight", "Firstthird", "Lastthird",
+}
+
+// Prayer is a timing and its time.
+type Prayer struct {
+	Name string
+	Time time.Time
+}
+
+// Schedule is the timings of a day, in the order of Names. Midnight and the
+// thirds of the night are on the same date as the others, so they can be
+// before Fajr.
+type Schedule struct {
+	Day     time.Time
+	Prayers []Prayer
+}
+
+// Time returns the time of the timing name, or the zero time if there is
+// no such timing.
+func (s Schedule) Time(name string) time.Time {
+	for _, p := range s.Prayers {
+		if p.Name == name {
+			return p.Time
+		}
+	}
+	return time.Time{}
+}
+
+// Next returns the first of the five prayers after t, or false if they
+// have all passed.
+func (s Schedule) Next(t time.Time) (Prayer, bool) {
+	for _, p := range s.Prayers {
+		switch p.Name {
+		case "Fajr", "Dhuhr", "Asr", "Maghrib", "Isha":
+			if p.Time.After(t) {
+				return p, true
+			}
+		}
+	}
+	return Prayer{}, false
+}
+
+// Compute returns the schedule of the date of day, in day's time zone. The
+// times aren't rounded, and those the sun never reaches that day, like
+// sunset in a polar day, are the zero time.
+func Compute(m Method, c Coordinates, day time.Time, o Options) Schedule {
+	y, mo, d := day.Date()
+	hours := Hours(m, c, day, o)
+	s := Schedule{Day: time.Date(y, mo, d, 0, 0, 0, 0, day.Location())}
+	for _, name := range Names {
+		if math.IsNaN(hours[name]) {
+			s.Prayers = append(s.Prayers, Prayer{Name: name})
+			continue
+		}
+		// Clock times, so those after a DST change at night are right, in
+		// seconds and nanoseconds as an int of nanoseconds overflows on 32
+		// bits.
+		at := time.Duration(hours[name] * float64(time.Hour))
+		t := time.Date(y, mo, d, 0, 0, int(at/time.Second), int(at%time.Second), day.Location())
+		s.Prayers = append(s.Prayers, Prayer{Name: name, Time: t})
+	}
+	return s
+}
+
+// Hours returns the timings of the date of day, in hours from midnight in
+// day's time zone. A timing the sun never reaches, as Sunrise near the
+// poles, is NaN.
+func Hours(m Method, c Coordinates, day time.Time, o Options) map[string]float64 {
+	if m.RamadanIshaMinutes > 0 && HijriDate(day).Month == 9 {
+		m.IshaMinutes = m.RamadanIshaMinutes
+	}
+
+	// Refraction and the sun's radius, and the horizon dipping lower seen
+	// from higher up.
+	sunAngle := 0.833 + 0.0347*math.Sqrt(math.Max(c.Elevation, 0))
+	lat := c.Latitude
+	y, mo, d := day.Date()
+
+	// Positions at a time of the day, in hours of local solar time.
+	jd := julianDay(y, int(mo), d) - c.Longitude/(15*24)
+	sun := func(t float64) (decl, eqt float64) {
+		return sunPosition(jd + t/24)
+	}
+	midDay := func(t float64) float64 {
+		_, eqt := sun(t)
+		return fixHours(12 - eqt)
+	}
+	angleTime := func(angle, t float64, beforeNoon bool) float64 {
+		decl, _ := sun(t)
+		x := (-dsin(angle) - dsin(decl)*dsin(lat)) / (dcos(decl) * dcos(lat))
+		d := darccos(x) / 15
+		if beforeNoon {
+			return midDay(t) - d
+		}
+		return midDay(t) + d
+	}
+	asrTime := func(factor, t float64) float64 {
+		decl, _ := sun(t)
+		return angleTime(-darccot(factor+dtan(math.Abs(lat-decl))), t, false)
+	}
+
+	fajr := angleTime(m.Fajr, 5, true)
+	sunrise := angleTime(sunAngle, 6, true)
+	dhuhr := midDay(12)
+	asr := asrTime(float64(1+o.School), 13)
+	sunset := angleTime(sunAngle, 18, false)
+
+	maghrib := sunset + m.MaghribMinutes/60
+	if m.Maghrib > 0 {
+		maghrib = angleTime(m.Maghrib, 18, false)
+	}
+	isha := maghrib + m.IshaMinutes/60
+	if m.IshaMinutes == 0 {
+		isha = angleTime(m.Isha, 18, false)
+	}
+
+	// Where the sun doesn't go deep enough below the horizon, as in summer
+	// at high latitudes, the angle based rule limits the times to a part of
+	// the night.
+	night := sunrise + 24 - sunset
+	limit := func(t, base, angle float64, before bool) float64 {
+		portion := angle / 60 * night
+		diff := t - base
+		if before {
+			diff = base - t
+		}
+		if math.IsNaN(t) || diff > portion {
+			if before {
+				return base - portion
+			}
+			return base + portion
+		}
+		return t
+	}
+	if m.Moonsighting {
+		// Fajr no earlier and Isha no later than the twilights of the season.
+		fajr, isha = seasonalTwilight(c.Latitude, o.Shafaq, day, sunrise, sunset, fajr, isha)
+	} else {
+		fajr = limit(fajr, sunrise, m.Fajr, true)
+		if m.IshaMinutes == 0 {
+			isha = limit(isha, sunset, m.Isha, false)
+		}
+	}
+	if m.Maghrib > 0 {
+		maghrib = limit(maghrib, sunset, m.Maghrib, false)
+	}
+
+	if o.JafariMidnight {
+		night = fajr + 24 - sunset
+	}
+
+	_, offset := time.Date(y, mo, d, 12, 0, 0, 0, day.Location()).Zone()
+	shift := float64(offset)/3600 - c.Longitude/15
+
+	times := map[string]float64{
+		"Imsak":      fajr - 10.0/60,
+		"Fajr":       fajr,
+		"Sunrise":    sunrise,
+		"Dhuhr":      dhuhr,
+		"Asr":        asr,
+		"Sunset":     sunset,
+		"Maghrib":    maghrib,
+		"Isha":       isha,
+		"Midnight":   fixHours(sunset + night/2),
+		"Firstthird": fixHours(sunset + night/3),
+		"Lastthird":  fixHours(sunset + night*2/3),
+	}
+	for name, t := range times {
+		times[name] = fixHours(t + shift)
+	}
+	return times
+}
+
+// seasonalTwilight limits fajr and isha, in hours, by the Moonsighting
+// Committee's twilights for latitude and the day of the year, Isha's
+// following shafaq.
+func seasonalTwilight(latitude float64, shafaq string, day time.Time, sunrise, sunset, fajr, isha float64) (float64, float64) {
+	lat := math.Abs(latitude)
+	year := day.Year()
+	days := 365
+	if time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC).YearDay() == 366 {
+		days = 366
+	}
+
+	// Days since the winter solstice.
+	dss := day.YearDay() + 10
+	if latitude < 0 {
+		dss = day.YearDay() - (days - 365 + 172)
+	}
+	dss = (dss + days) % days
+
+	// Minutes of twilight at the solstices and between, a and d being
+	// the winter and summer ones.
+	season := func(a, b, c, d float64) float64 {
+		t := float64(dss)
+		switch {
+		case dss < 91:
+			return a + (b-a)/91*t
+		case dss < 137:
+			return b + (c-b)/46*(t-91)
+		case dss < 183:
+			return c + (d-c)/46*(t-137)
+		case dss < 229:
+			return d + (c-d)/46*(t-183)
+		case dss < 275:
+			return c + (b-c)/46*(t-229)
+		}
+		return b + (a-b)/91*(t-275)
+	}
+
+	morning := season(75+28.65/55*lat, 75+19.44/55*lat, 75+32.74/55*lat, 75+48.10/55*lat)
+	var evening float64
+	switch shafaq {
+	case "ahmer":
+		evening = season(62+17.40/55*lat, 62-7.16/55*lat, 62+5.12/55*lat, 62+19.44/55*lat)
+	case "abyad":
+		evening = season(75+25.60/55*lat, 75+7.16/55*lat, 75+36.84/55*lat, 75+81.84/55*lat)
+	default:
+		evening = season(75+25.60/55*lat, 75+2.050/55*lat, 75-9.21/55*lat, 75+6.14/55*lat)
+	}
+
+	if safe := sunrise - morning/60; math.IsNaN(fajr) || fajr < safe {
+		fajr = safe
+	}
+	if safe := sunset + evening/60; math.IsNaN(isha) || isha > safe {
+		isha = safe
+	}
+	return fajr, isha
+}
+
+// sunPosition returns the declination of the sun in degrees and the
+// equation of time in hours at Julian day jd.
+func sunPosition(jd float64) (decl, eqt float64) {
+	d := jd - 2451545.0
+	g := fixAngle(357.529 + 0.98560028*d)
+	q := fixAngle(280.459 + 0.98564736*d)
+	l := fixAngle(q + 1.915*dsin(g) + 0.020*dsin(2*g))
+	e := 23.439 - 0.00000036*d
+
+	ra := darctan2(dcos(e)*dsin(l), dcos(l)) / 15
+	eqt = q/15 - fixHours(ra)
+	decl = darcsin(dsin(e) * dsin(l))
+	return decl, eqt
+}
+
+// julianDay returns the Julian day at the midnight starting y-m-d.
+func julianDay(y, m, d int) float64 {
+	if m <= 2 {
+		y--
+		m += 12
+	}
+	a := math.Floor(float64(y) / 100)
+	b := 2 - a + math.Floor(a/4)
+	return math.Floor(365.25*float64(y+4716)) + math.Floor(30.6001*float64(m+1)) + float64(d) + b - 1524.5
+}
+
+// Trigonometry in degrees.
+
+func dsin(d float64) float64        { return math.Sin(d * math.Pi / 180) }
+func dcos(d float64) float64        { return math.Cos(d * math.Pi / 180) }
+func dtan(d float64) float64        { return math.Tan(d * math.Pi / 180) }
+func darcsin(x float64) float64     { return math.Asin(x) * 180 / math.Pi }
+func darccos(x float64) float64     { return math.Acos(x) * 180 / math.Pi }
+func darctan2(y, x float64) float64 { return math.Atan2(y, x) * 180 / math.Pi }
+func darccot(x float64) float64     { return math.Atan(1/x) * 180 / math.Pi }
+
+func fixAngle(a float64) float64 { return a - 360*math.Floor(a/360) }
+func fixHours(h float64) float64 { return h - 24*math.Floor(h/24) }
@@ -0,0 +1,64 @@
+package praytime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeMakkah(t *testing.T) {
+	zone := time.FixedZone("+03", 3*3600)
+	day := time.Date(2024, time.March, 1, 0, 0, 0, 0, zone)
+	s := Compute(Methods[4], Coordinates{Latitude: 21.4225, Longitude: 39.8262}, day, Options{})
+
+	want := map[string]string{
+		"Fajr": "05:25", "Sunrise": "06:41", "Dhuhr": "12:33",
+		"Asr": "15:54", "Maghrib": "18:25", "Isha": "19:55",
+	}
+	for name, clock := range want {
+		got := s.Time(name)
+		w, _ := time.ParseInLocation("2006-01-02 15:04", "2024-03-01 "+clock, zone)
+		if d := got.Sub(w); d < -2*time.Minute || d > 2*time.Minute {
+			t.Errorf("%s = %s, want about %s", name, got.Format("15:04"), clock)
+		}
+	}
+}
+
+func TestComputePolarDay(t *testing.T) {
+	zone, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Skip(err)
+	}
+	day := time.Date(2024, time.June, 21, 0, 0, 0, 0, zone)
+	s := Compute(Methods[3], Coordinates{Latitude: 69.6492, Longitude: 18.9553}, day, Options{})
+
+	for _, name := range []string{"Sunrise", "Sunset", "Maghrib"} {
+		if got := s.Time(name); !got.IsZero() {
+			t.Errorf("%s = %s, want the zero time in a polar day", name, got)
+		}
+	}
+	for _, p := range s.Prayers {
+		if !p.Time.IsZero() && p.Time.Year() != 2024 {
+			t.Errorf("%s = %s, not on the day", p.Name, p.Time)
+		}
+	}
+	if dhuhr := s.Time("Dhuhr"); dhuhr.IsZero() {
+		t.Error("Dhuhr is missing")
+	}
+}
+
+// TestComputeHours checks the times are those of Hours, as they weren't
+// where int is 32 bits; go test with GOARCH=386 to run it there.
+func TestComputeHours(t *testing.T) {
+	zone := time.FixedZone("+03", 3*3600)
+	day := time.Date(2024, time.March, 1, 0, 0, 0, 0, zone)
+	c := Coordinates{Latitude: 21.4225, Longitude: 39.8262}
+	hours := Hours(Methods[4], c, day, Options{})
+	s := Compute(Methods[4], c, day, Options{})
+
+	for _, p := range s.Prayers {
+		want := day.Add(time.Duration(hours[p.Name] * float64(time.Hour)))
+		if d := p.Time.Sub(want); d < -time.Second || d > time.Second {
+			t.Errorf("%s = %s, want %s", p.Name, p.Time, want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// The months after the current one whose timings are kept cached, getting
+// them in the background as each day starts, for the app to keep working
+// offline that long, such as over a trip. 0 gets each month as it comes.
+var prefetchMonths = 0
+
+// prefetchLocations returns the places whose timings are prefetched: the
+// active location and the profiles.
+func prefetchLocations() []Location {
+	locs := []Location{location}
+	for _, p := range profiles {
+		if !p.Is(location) {
+			locs = append(locs, p)
+		}
+	}
+	return locs
+}
+
+// Prefetch caches the timings at loc of months months, from the month of
+// from.
+func Prefetch(loc Location, from time.Time, months int) error {
+	for i := 0; i < months; i++ {
+		month := time.Date(from.Year(), from.Month()+time.Month(i), 1, 0, 0, 0, 0, time.Local)
+		if _, err := cacheTimings(loc, month); err != nil {
+			return fmt.Errorf("%s %s: %w", loc.Name, month.Format("2006-01"), err)
+		}
+	}
+	return nil
+}
+
+// updateCache cleans the cache and prefetches tomorrow at the first of
+// locs and the prefetchMonths at all of them, in the background as a day
+// starts.
+func (s *Scheduler) updateCache(locs []Location) {
+	defer s.Recover("cache")
+	now := time.Now()
+	if _, _, err := CleanCache(now, false); err != nil {
+		fmt.Fprintln(os.Stderr, "cache:", err)
+	}
+	// Tomorrow is got now rather than after Isha, to show its Fajr then
+	// even if the network is down by then.
+	if _, err := cacheTimings(locs[0], now.AddDate(0, 0, 1)); err != nil {
+		fmt.Fprintln(os.Stderr, "prefetch:", err)
+	}
+	if prefetchMonths == 0 {
+		return
+	}
+	for _, loc := range locs {
+		if err := Prefetch(loc, now, prefetchMonths+1); err != nil {
+			fmt.Fprintln(os.Stderr, "prefetch:", err)
+		}
+	}
+}
+
+// prefetchCommand caches the timings of the rest of a year at the active
+// location and the profiles, to be used offline.
+func prefetchCommand(args []string) int {
+	fs := flag.NewFlagSet("prefetch", flag.ExitOnError)
+	dir := fs.String("dir", "", "directory with the timings")
+	now := time.Now()
+	year := fs.Int("year", now.Year(), "year to cache")
+	fs.Parse(args)
+	if err := openForCommand(*dir); err != nil {
+		fmt.Fprintln(os.Stderr, "prefetch:", err)
+		return 1
+	}
+
+	from := time.Date(*year, time.January, 1, 0, 0, 0, 0, time.Local)
+	if *year == now.Year() {
+		from = time.Date(*year, now.Month(), 1, 0, 0, 0, 0, time.Local)
+	} else if *year < now.Year() {
+		fmt.Fprintf(os.Stderr, "prefetch: %d is over\n", *year)
+		return 1
+	}
+	months := 13 - int(from.Month())
+
+	failed := false
+	for _, loc := range prefetchLocations() {
+		if err := Prefetch(loc, from, months); err != nil {
+			fmt.Fprintln(os.Stderr, "prefetch:", err)
+			failed = true
+			continue
+		}
+		fmt.Printf("%s: %d months cached\n", loc.Name, months)
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
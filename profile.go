@@ -0,0 +1,31 @@
+package main
+
+import "fmt"
+
+// ProfileLabel names profile p and its method, as "Office — ISNA".
+func ProfileLabel(p Location) string {
+	cm, ok := calcMethods[p.CalcMethod()]
+	if !ok {
+		return p.Name
+	}
+	return fmt.Sprintf("%s — %s", p.Name, cm.Name)
+}
+
+// SwitchProfile makes p the active location and remembers it for the next
+// start.
+func SwitchProfile(p Location) {
+	location = p
+	SetState("profile", p.Name)
+}
+
+// RestoreProfile makes the profile active when last running active again, if
+// it's still in the config.
+func RestoreProfile() {
+	name := State("profile")
+	for _, p := range profiles {
+		if name != "" && p.Name == name {
+			location = p
+			return
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import "math"
+
+const (
+	kaabaLatitude  = 21.422487
+	kaabaLongitude = 39.826206
+)
+
+// QiblaBearing returns the initial great-circle bearing from lat, lon to the
+// Kaaba in degrees clockwise from true north.
+func QiblaBearing(lat, lon float64) float64 {
+	phi1 := lat * math.Pi / 180
+	phi2 := kaabaLatitude * math.Pi / 180
+	dLambda := (kaabaLongitude - lon) * math.Pi / 180
+
+	y := math.Sin(dLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+
+	bearing := math.Atan2(y, x) * 180 / math.Pi
+	return math.Mod(bearing+360, 360)
+}
@@ -0,0 +1,80 @@
+//go:build !gio
+
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gen2brain/iup-go/iup"
+)
+
+// qiblaPanel returns a frame with a compass rose pointing to the Qibla, and a
+// function to redraw it after the location changes.
+func qiblaPanel() (iup.Ihandle, func()) {
+	canvas := iup.Canvas()
+	iup.SetAttribute(canvas, "RASTERSIZE", pxSize(150, 150))
+	canvas.SetAttributes(map[string]string{
+		"BORDER":   "NO",
+		"CANFOCUS": "NO",
+	})
+
+	iup.SetCallback(canvas, "ACTION", iup.CanvasActionFunc(func(ih iup.Ihandle, posx, posy float64) int {
+		bearing := QiblaBearing(location.Latitude, location.Longitude)
+
+		iup.DrawBegin(ih)
+		defer iup.DrawEnd(ih)
+
+		iup.DrawParentBackground(ih)
+
+		w, h := iup.DrawGetSize(ih)
+		cx, cy := w/2, h/2
+		r := cx
+		if cy < r {
+			r = cy
+		}
+		r -= px(20)
+
+		iup.SetAttribute(ih, "DRAWCOLOR", "0 0 0")
+		iup.SetAttribute(ih, "DRAWSTYLE", "STROKE")
+		iup.DrawArc(ih, cx-r, cy-r, cx+r, cy+r, 0, 360)
+
+		for _, d := range []struct {
+			label string
+			dx    int
+			dy    int
+		}{{"N", 0, -1}, {"E", 1, 0}, {"S", 0, 1}, {"W", -1, 0}} {
+			tw, th := iup.DrawGetTextSize(ih, d.label)
+			x := cx + d.dx*(r+px(10)) - tw/2
+			y := cy + d.dy*(r+px(10)) - th/2
+			iup.DrawText(ih, d.label, x, y, -1, -1)
+		}
+
+		// Screen y grows downwards, so north is -y.
+		rad := bearing * math.Pi / 180
+		x := cx + int(float64(r)*math.Sin(rad))
+		y := cy - int(float64(r)*math.Cos(rad))
+
+		iup.SetAttribute(ih, "DRAWCOLOR", "0 128 0")
+		iup.SetAttribute(ih, "DRAWLINEWIDTH", px(3))
+		iup.DrawLine(ih, cx, cy, x, y)
+		iup.SetAttribute(ih, "DRAWLINEWIDTH", px(1))
+		return iup.DEFAULT
+	}))
+
+	degrees := iup.Label(fmt.Sprintf("%.1f° from North", QiblaBearing(location.Latitude, location.Longitude)))
+	iup.SetAttribute(degrees, "ALIGNMENT", "ACENTER")
+	iup.SetAttribute(degrees, "EXPAND", "HORIZONTAL")
+
+	vbox := iup.Vbox(canvas, degrees)
+	iup.SetAttribute(vbox, "ALIGNMENT", "ACENTER")
+
+	frame := iup.Frame(vbox)
+	iup.SetAttribute(frame, "TITLE", "Qibla")
+
+	refresh := func() {
+		iup.SetAttribute(degrees, "TITLE", fmt.Sprintf("%.1f° from North", QiblaBearing(location.Latitude, location.Longitude)))
+		iup.Update(canvas)
+	}
+	return frame, refresh
+}
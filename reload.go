@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"time"
+)
+
+// Apply changes to the config file while running. Settings removed from the
+// file keep their current values until a restart.
+var configReload = true
+
+// watchConfig sends on changed whenever the config file is modified,
+// checking its modification time every 2 seconds.
+func watchConfig(changed chan<- bool) {
+	modTime := func() time.Time {
+		fi, err := os.Stat(configPath)
+		if err != nil {
+			return time.Time{}
+		}
+		return fi.ModTime()
+	}
+
+	last := modTime()
+	for range time.Tick(2 * time.Second) {
+		if t := modTime(); !t.Equal(last) {
+			last = t
+			select {
+			case changed <- true:
+			default:
+			}
+		}
+	}
+}
+
+// ReloadConfig applies the config file again and reschedules, fetching the
+// timings again if the location or method changed.
+func (s *Scheduler) ReloadConfig() error {
+	if err := LoadConfig(); err != nil {
+		return err
+	}
+	RestoreProfile()
+	s.Reload()
+	return nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// apiMonth is a month calendar as the AlAdhan API returns it, and as
+// WriteCalendar computes it, with the fields read here. Data is the error
+// message instead of the days when Code isn't 200.
+type apiMonth struct {
+	Code   int             `json:"code,omitempty"`
+	Status string          `json:"status,omitempty"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// apiDay is a day of a month calendar.
+type apiDay struct {
+	Timings map[string]string `json:"timings"`
+	Date    apiDate           `json:"date"`
+	Meta    apiMeta           `json:"meta"`
+}
+
+type apiDate struct {
+	Hijri apiHijri `json:"hijri"`
+}
+
+type apiHijri struct {
+	Day   json.Number   `json:"day"`
+	Year  json.Number   `json:"year"`
+	Month apiHijriMonth `json:"month"`
+}
+
+type apiHijriMonth struct {
+	Number json.Number `json:"number"`
+	En     string      `json:"en"`
+}
+
+type apiMeta struct {
+	Timezone string `json:"timezone"`
+}
+
+// DecodeMonth reads and checks a month calendar, with errors naming what's
+// wrong in it like "missing data[5].timings.Fajr". strict rejects fields
+// not read here too, for the calendars computed here whose fields are all
+// known, while the API's carry many more.
+func DecodeMonth(data []byte, strict bool) ([]apiDay, error) {
+	decode := func(data []byte, v interface{}) error {
+		d := json.NewDecoder(bytes.NewReader(data))
+		if strict {
+			d.DisallowUnknownFields()
+		}
+		return d.Decode(v)
+	}
+
+	var month apiMonth
+	if err := decode(data, &month); err != nil {
+		return nil, err
+	}
+	if month.Code != 0 && month.Code != http.StatusOK {
+		var msg string
+		json.Unmarshal(month.Data, &msg)
+		return nil, fmt.Errorf("%d %s: %s", month.Code, month.Status, msg)
+	}
+	if month.Data == nil {
+		return nil, fmt.Errorf("missing data")
+	}
+	var days []apiDay
+	if err := decode(month.Data, &days); err != nil {
+		return nil, fmt.Errorf("data: %w", err)
+	}
+	for i := range days {
+		if err := days[i].normalize(fmt.Sprintf("data[%d]", i)); err != nil {
+			return nil, err
+		}
+	}
+	return days, nil
+}
+
+// normalize checks d, at path in the calendar, has the fields read from it
+// and that they parse, dropping the timings added to the API that don't.
+func (d *apiDay) normalize(path string) error {
+	for _, name := range prayerNames {
+		if _, ok := d.Timings[name]; !ok {
+			return fmt.Errorf("missing %s.timings.%s", path, name)
+		}
+	}
+	for name, v := range d.Timings {
+		if _, _, _, err := splitTiming(v); err != nil {
+			if !contains(prayerNames, name) {
+				delete(d.Timings, name)
+				continue
+			}
+			return fmt.Errorf("%s.timings.%s: %w", path, name, err)
+		}
+	}
+	h := d.Date.Hijri
+	for _, f := range []struct {
+		name string
+		v    json.Number
+	}{{"day", h.Day}, {"month.number", h.Month.Number}, {"year", h.Year}} {
+		if _, err := apiNumber(string(f.v)); err != nil {
+			return fmt.Errorf("%s.date.hijri.%s: %w", path, f.name, err)
+		}
+	}
+	if m, _ := apiNumber(string(h.Month.Number)); m < 1 || m > 12 {
+		return fmt.Errorf("%s.date.hijri.month.number: %d is not a month", path, m)
+	}
+	return nil
+}
+
+// badResponsePath is where a response of the API that can't be read is
+// saved, to see what it was.
+func badResponsePath() string {
+	return timingsDir + "aladhan-response.json"
+}
+
+// fetchTimings downloads the month calendar at url from the API and checks
+// it, saving it in badResponsePath if it can't be read.
+func fetchTimings(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := DecodeMonth(data, false); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			err = fmt.Errorf("%s: %w", resp.Status, err)
+		}
+		saved := badResponsePath()
+		if werr := os.WriteFile(saved, data, 0644); werr != nil {
+			saved = werr.Error()
+		}
+		return nil, fmt.Errorf("unexpected response from AlAdhan: %w (saved in %s)", err, saved)
+	}
+	return data, nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+// uiScale multiplies fonts, margins and the icon, for high-DPI displays. 0
+// detects it from the screen DPI.
+var uiScale = 0.0
+
+// iconSizes are the sizes the icon is rendered at, in pixels.
+var iconSizes = []int{16, 22, 24, 32, 48, 64}
+
+// ResizeIcon returns img scaled to a size×size square.
+func ResizeIcon(img image.Image, size int) image.Image {
+	return ResizeImage(img, size, size)
+}
+
+// ResizeImage returns img scaled to w×h pixels.
+func ResizeImage(img image.Image, w, h int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+	return dst
+}
+
+// iconFor returns the smallest of iconSizes at least size, or the largest.
+func iconFor(size int) int {
+	for _, s := range iconSizes {
+		if s >= size {
+			return s
+		}
+	}
+	return iconSizes[len(iconSizes)-1]
+}
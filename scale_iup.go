@@ -0,0 +1,50 @@
+//go:build !gio
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strconv"
+
+	"github.com/gen2brain/iup-go/iup"
+)
+
+// scale is uiScale, or the screen DPI over 96 rounded to a quarter, set by
+// initScale.
+var scale = 1.0
+
+// initScale sets scale once IUP is open.
+func initScale() {
+	scale = uiScale
+	if scale <= 0 {
+		dpi, _ := strconv.ParseFloat(iup.GetGlobal("SCREENDPI"), 64)
+		scale = math.Round(dpi/96*4) / 4
+	}
+	if scale < 1 {
+		scale = 1
+	}
+}
+
+// px scales n pixels.
+func px(n int) int {
+	return int(math.Round(float64(n) * scale))
+}
+
+// pxSize returns a scaled "WxH" size attribute.
+func pxSize(w, h int) string {
+	return fmt.Sprintf("%dx%d", px(w), px(h))
+}
+
+// scaled returns n scaled, as an attribute value for font sizes and gaps.
+func scaled(n int) string {
+	return strconv.Itoa(px(n))
+}
+
+// setIcons registers img resized for the tray as the "icon" handle, and for
+// window titles as "windowicon".
+func setIcons(img image.Image) {
+	iup.ImageFromImage(ResizeIcon(img, iconFor(px(16)))).SetHandle("icon")
+	iup.ImageFromImage(ResizeIcon(img, iconFor(px(32)))).SetHandle("windowicon")
+}
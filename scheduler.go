@@ -0,0 +1,317 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Snooze puts a reminder off for snoozeDuration, up to snoozeMax times.
+var (
+	snoozeDuration = 2 * time.Minute
+	snoozeMax      = 3
+)
+
+// Scheduler fires the reminders, adhan, window alerts and events of the
+// active location, publishing them on Bus. Tick has to be called every
+// second, from the GUI thread when the hooks or subscribers touch the GUI.
+type Scheduler struct {
+	Prayers  Prayers // today's, or tomorrow's once Isha has passed
+	Today    Prayers // today's, still after Isha
+	Tomorrow Prayers // tomorrow's, for the prayers coming up after Prayers
+
+	Next       Prayer    // the upcoming prayer
+	Current    Prayer    // the latest prayer
+	CurrentEnd time.Time // end of Current's window
+	Makruh     []Period  // today's makruh times
+	Hijri      HijriDate // today's Hijri date
+	Eid        Prayer    // today's Eid prayer, zero when not an Eid
+	Imsak      Prayer    // today's Imsak in Ramadan, zero otherwise
+	Extra      []Prayer  // today's extraTimings
+	DSTNotice  string    // when the clocks change today
+
+	// Notify shows a notification. It must be set.
+	Notify func(title, message string)
+	// ShowText shows an event's text, ignored when nil.
+	ShowText func(title, text string)
+	// AdhanDone is called from another goroutine once the adhan finished.
+	AdhanDone func()
+	// NotifyReminder shows a reminder's notification with a way to snooze
+	// it. Notify is used when nil.
+	NotifyReminder func(title, message string)
+	// Flash draws the eye to the window when a sound couldn't be played,
+	// ignored when nil.
+	Flash func()
+
+	// Muted silences the sounds. Alerts are still shown and recorded.
+	// It's changed with SetMuted.
+	Muted bool
+
+	// Bus has the alerts and changes of the schedule. The hooks above are
+	// subscribed to it by NewScheduler.
+	Bus *Bus
+
+	location    Location // of the schedule
+	queued      chan func()
+	wakeMu      sync.Mutex
+	wake        func()
+	windowEnded bool
+	events      []Event
+	day         int
+	escalations int       // escalations of Current fired
+	dismissed   bool      // Current's escalation was dismissed
+	reminder    Alert     // the latest reminder, for Snooze
+	snoozes     int       // times reminder was snoozed
+	snoozed     time.Time // when to repeat reminder
+	errorsShown map[string]time.Time
+}
+
+func NewScheduler() *Scheduler {
+	s := &Scheduler{Prayers: make(Prayers, len(prayerNames)), Bus: NewBus(), queued: make(chan func(), 16)}
+	s.Bus.Failed = s.failed
+	for _, topic := range []string{ReminderDue, AdhanDue, AlertDue} {
+		s.Bus.Subscribe(topic, s.notifyAlert)
+	}
+	s.Bus.Subscribe(TextDue, func(ev BusEvent) {
+		if s.ShowText != nil {
+			s.ShowText(ev.Alert.Name, ev.Text)
+		}
+	})
+	s.Bus.Subscribe(TimingsUpdated, s.scheduleToasts)
+	s.Bus.Subscribe(AdhanFinished, func(BusEvent) {
+		if s.AdhanDone != nil {
+			s.AdhanDone()
+		}
+	})
+	s.Reload()
+	s.restoreSnooze()
+	return s
+}
+
+// Reload recomputes the schedule, after the location changed for example.
+func (s *Scheduler) Reload() {
+	defer s.Recover("reload")
+	now := time.Now()
+	s.Today = PrayerTimings(location, now)
+	copy(s.Prayers, s.Today)
+
+	s.Next, _ = NextPrayer(location, s.Prayers)
+	s.Current = CurrentPrayer(location, s.Prayers)
+	s.CurrentEnd = WindowEnd(location, s.Current)
+	s.windowEnded = !now.Before(s.CurrentEnd)
+	s.loadDay(now)
+
+	if !s.location.Is(location) {
+		s.location = location
+		s.Bus.Publish(BusEvent{Topic: LocationChanged, Location: location})
+	}
+	s.Bus.Publish(BusEvent{Topic: TimingsUpdated})
+}
+
+func (s *Scheduler) loadDay(now time.Time) {
+	// Set first, so a failure below isn't retried every Tick before the
+	// reminders are checked.
+	s.day = now.YearDay()
+	go s.updateCache(prefetchLocations())
+
+	s.events = DayEvents(location, now)
+	timings := DayTimings(location, now)
+	s.Makruh = MakruhTimes(timings)
+	s.Hijri = Hijri(location, now)
+	s.Eid, _ = EidPrayer(s.Hijri, now, timings)
+	s.Imsak, _ = ImsakTime(s.Hijri, timings)
+	s.DSTNotice = ""
+	if at, shift, ok := DSTChange(timings["Fajr"]); ok {
+		s.DSTNotice = FormatDSTChange(at, shift)
+	}
+	s.Tomorrow = nil
+	tomorrow := now.AddDate(0, 0, 1)
+	if _, err := cacheTimings(location, tomorrow); err != nil {
+		fmt.Fprintln(os.Stderr, "tomorrow:", err)
+	} else {
+		s.Tomorrow = PrayerTimings(location, tomorrow)
+	}
+	s.Extra = nil
+	for _, name := range extraTimings {
+		if t, ok := timings[name]; ok {
+			s.Extra = append(s.Extra, Prayer{Name: name, Time: t})
+		}
+	}
+}
+
+// Tick fires whatever is due at now. It reports whether Prayers changed,
+// rolling over to the next day or becoming the day's at midnight, and
+// whether a new day started. A panic is recovered and
+// shown, Tick doing the rest of its work at the next call.
+func (s *Scheduler) Tick(now time.Time) (timingsChanged, dayChanged bool) {
+	defer s.Recover("schedule")
+	s.RunQueued()
+
+	if now.YearDay() != s.day {
+		// The table becomes the day's at midnight, or when waking up from
+		// a sleep over it, rather than the one NextPrayer rolled over to.
+		s.loadDay(now)
+		s.Today = PrayerTimings(location, now)
+		copy(s.Prayers, s.Today)
+		dayChanged, timingsChanged = true, true
+	}
+	np, rolled := NextPrayer(location, s.Prayers)
+	timingsChanged = timingsChanged || rolled
+
+	if !np.Time.Equal(s.Next.Time) {
+		s.Current, s.Next = s.Next, np
+		s.CurrentEnd = WindowEnd(location, s.Current)
+		s.windowEnded = false
+		s.escalations, s.dismissed = 0, false
+	}
+
+	windowRem := s.CurrentEnd.Sub(now).Round(time.Second)
+	if windowRem <= 0 && !s.windowEnded {
+		WindowEnded(s.Current)
+		s.windowEnded = true
+	}
+	if windowAlert.Enabled && windowRem == time.Duration(windowAlert.Before) && !IsPrayed(s.Current) {
+		s.alert("Window", s.Current.Name, FormatWindow(s.Current, windowRem), windowAlert.Sound)
+	}
+
+	rem := np.Time.Sub(now).Round(time.Second)
+	for _, r := range RemindersFor(np.Name) {
+		if rem != time.Duration(r.Before) {
+			continue
+		}
+		msg := ""
+		if r.Notify && countdownFormat == "time" {
+			msg = fmt.Sprintf("%s %s", np.Label(), FormatUntil("in", np.Time))
+		} else if r.Notify {
+			msg = fmt.Sprintf("%s in %v minutes", np.Label(), time.Duration(r.Before).Minutes())
+		}
+		s.alert("Reminder", np.Name, msg, r.Sound)
+		s.reminder = Alert{Kind: "Reminder", Name: np.Name, Message: msg, Sound: r.Sound}
+		s.snoozes = 0
+		s.snoozed = time.Time{}
+	}
+
+	RefreshMeetings()
+	if rem == time.Second {
+		if m, busy := CurrentMeeting(now); busy {
+			s.alert("Adhan", np.Name, fmt.Sprintf("Time for %s, muted during %s", np.Label(), m.Summary), "")
+		} else {
+			s.alert("Adhan", np.Name, "", adhanSound)
+		}
+	}
+	s.escalate(now)
+
+	if timingsChanged {
+		s.Bus.Publish(BusEvent{Topic: TimingsUpdated})
+	}
+	for _, ev := range s.events {
+		if ev.Time.Sub(now).Round(time.Second) == 0 {
+			s.fireEvent(ev)
+		}
+	}
+
+	// A snoozed reminder is dropped once its prayer came.
+	if !s.snoozed.IsZero() && !now.Before(s.snoozed) {
+		s.snoozed = time.Time{}
+		if r := s.reminder; r.Name == s.Next.Name {
+			s.alert(r.Kind, r.Name, r.Message, r.Sound)
+		}
+	}
+
+	return timingsChanged, dayChanged
+}
+
+// Do runs f on the scheduler's goroutine at the next Tick, or RunQueued,
+// for the servers and other goroutines to use s.
+func (s *Scheduler) Do(f func()) {
+	s.queued <- f
+	s.wakeUp()
+}
+
+// TryDo is Do giving up if f can't be queued within timeout, as when the
+// scheduler is stuck with its queue full. It reports whether f was queued.
+func (s *Scheduler) TryDo(f func(), timeout time.Duration) bool {
+	select {
+	case s.queued <- f:
+	case <-time.After(timeout):
+		return false
+	}
+	s.wakeUp()
+	return true
+}
+
+// wakeUp calls the function set by SetWake, if any.
+func (s *Scheduler) wakeUp() {
+	s.wakeMu.Lock()
+	wake := s.wake
+	s.wakeMu.Unlock()
+	if wake != nil {
+		wake()
+	}
+}
+
+// SetMuted mutes or unmutes s, scheduling the lock screen toasts again
+// to match.
+func (s *Scheduler) SetMuted(muted bool) {
+	if muted != s.Muted {
+		s.Muted = muted
+		s.scheduleToasts(BusEvent{})
+	}
+}
+
+// SetWake has Do call wake, from the goroutine calling Do, for a frontend
+// sleeping between the events of the schedule to call RunQueued.
+func (s *Scheduler) SetWake(wake func()) {
+	s.wakeMu.Lock()
+	s.wake = wake
+	s.wakeMu.Unlock()
+}
+
+// RunQueued runs the functions queued by Do.
+func (s *Scheduler) RunQueued() {
+	for {
+		select {
+		case f := <-s.queued:
+			f()
+		default:
+			return
+		}
+	}
+}
+
+// Snooze repeats the latest reminder after d. It returns false if there is
+// none, or it was snoozed snoozeMax times already.
+func (s *Scheduler) Snooze(d time.Duration) bool {
+	if s.reminder.Kind == "" || s.reminder.Name != s.Next.Name || s.snoozes >= snoozeMax {
+		return false
+	}
+	s.snoozes++
+	s.snoozed = time.Now().Add(d)
+	return true
+}
+
+// MakruhAt returns the makruh time now is in, if any.
+func (s *Scheduler) MakruhAt(now time.Time) (Period, bool) {
+	for _, p := range s.Makruh {
+		if p.Contains(now) {
+			return p, true
+		}
+	}
+	return Period{}, false
+}
+
+func (s *Scheduler) fireEvent(ev Event) {
+	if ev.Action != nil {
+		ev.Action()
+		return
+	}
+	msg := ev.Message
+	if msg == "" {
+		msg = ev.Name + " time"
+	}
+	s.alertVolume("Event", ev.Name, msg, ev.Sound, ev.Volume)
+	if ev.Text != "" {
+		s.Bus.Publish(BusEvent{Topic: TextDue, Alert: Alert{Name: ev.Name}, Text: ev.Text})
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// Starlark scripts run on the scheduler's events, loaded on startup. A
+// script defines on_event(event) to be called with each one, such as:
+//
+//	def on_event(event):
+//	    if event.name == "Fajr" and event.kind == "Adhan" and event.weekday == "Friday":
+//	        notify("Jumu'ah", "Read Surat al-Kahf today")
+//
+// event has topic, kind, name, message, text, time ("15:04"), weekday, next (the
+// upcoming prayer) and location. The scripts can call notify(title,
+// message), play(sound) and print.
+var scripts = []string{}
+
+// Steps a script may take for an event before being stopped.
+const scriptSteps = 1000000
+
+// StartScripts loads the scripts and subscribes them to the events of s.
+func StartScripts(s *Scheduler) {
+	builtins := starlark.StringDict{
+		"notify": starlark.NewBuiltin("notify", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var title, message string
+			if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "title", &title, "message", &message); err != nil {
+				return nil, err
+			}
+			s.Notify(title, message)
+			return starlark.None, nil
+		}),
+		"play": starlark.NewBuiltin("play", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var sound string
+			if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "sound", &sound); err != nil {
+				return nil, err
+			}
+			if !s.Muted {
+				go func() {
+					defer s.Recover("scripts: play")
+					if err := PlaySound(sound); err != nil {
+						s.soundFailed(err)
+					}
+				}()
+			}
+			return starlark.None, nil
+		}),
+	}
+
+	for _, path := range scripts {
+		path := path
+		thread := scriptThread(path)
+		globals, err := starlark.ExecFile(thread, path, nil, builtins)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "scripts:", err)
+			continue
+		}
+		onEvent, ok := globals["on_event"].(starlark.Callable)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "scripts: %s has no on_event function\n", path)
+			continue
+		}
+
+		run := func(ev BusEvent) {
+			event := scriptEvent(s, ev)
+			if _, err := starlark.Call(scriptThread(path), onEvent, starlark.Tuple{event}, nil); err != nil {
+				fmt.Fprintln(os.Stderr, "scripts:", err)
+			}
+		}
+		// Not AdhanFinished, which comes from another goroutine.
+		for _, topic := range []string{TimingsUpdated, LocationChanged, ReminderDue, AdhanDue, AlertDue, TextDue} {
+			s.Bus.Subscribe(topic, run)
+		}
+	}
+}
+
+func scriptThread(path string) *starlark.Thread {
+	thread := &starlark.Thread{
+		Name: path,
+		Print: func(_ *starlark.Thread, msg string) {
+			fmt.Printf("%s: %s\n", path, msg)
+		},
+	}
+	thread.SetMaxExecutionSteps(scriptSteps)
+	return thread
+}
+
+// scriptEvent is the event argument of on_event.
+func scriptEvent(s *Scheduler, ev BusEvent) starlark.Value {
+	t := ev.Alert.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return starlarkstruct.FromStringDict(starlark.String("event"), starlark.StringDict{
+		"topic":    starlark.String(ev.Topic),
+		"kind":     starlark.String(ev.Alert.Kind),
+		"name":     starlark.String(ev.Alert.Name),
+		"message":  starlark.String(ev.Alert.Message),
+		"text":     starlark.String(ev.Text),
+		"time":     starlark.String(t.Format("15:04")),
+		"weekday":  starlark.String(t.Weekday().String()),
+		"next":     starlark.String(s.Next.Name),
+		"location": starlark.String(location.Name),
+	})
+}
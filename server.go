@@ -0,0 +1,374 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"ahmed/prayer/prayerpb"
+)
+
+// Serve the schedule as JSON on Addr, for dashboards and home automation:
+// GET /api/status is the Status now, /api/timings?date=2006-01-02 the
+// prayers of a day, at the active location or any place given as lat and
+// lon, /api/announcements the announcements of signage mode, which PUT
+// replaces, and /api/events the events of the scheduler's Bus as
+// server-sent events, of the topic parameters or all of them, as described
+// by the OpenAPI document at /api/openapi.json; /announcements is a page to
+// edit the announcements. For managing kiosks, GET /api/health is how the
+// server is doing, and POST /api/reload applies the config file again and
+// /api/restart computes the schedule again; prayer remote makes these
+// requests. The schedule and events are also served over gRPC on GRPCAddr,
+// following prayerpb/prayer.proto.
+//
+// With a Token, requests need it as a bearer token in the Authorization
+// header, or the token parameter, and gRPC calls in their authorization
+// metadata. Without one, only this machine can change the server. TLS
+// serves both over TLS, with the certificate and key of CertFile and
+// KeyFile or else a self-signed pair made in server.crt and server.key.
+//
+// Each client address gets RateLimit requests a second, in bursts of up to
+// twice that, and Status and Timings are reused for CacheFor, until the
+// timings are reloaded.
+//
+// Debug also serves the profiles of net/http/pprof at /debug/pprof/ and the
+// memory stats, goroutines and uptime of the app at /debug/vars, to look
+// into the memory or CPU used on a small machine like a Raspberry Pi.
+var server = struct {
+	Enabled   bool
+	Addr      string
+	GRPCAddr  string
+	Token     string
+	TLS       bool
+	CertFile  string
+	KeyFile   string
+	RateLimit float64
+	CacheFor  Duration
+	Debug     bool
+}{
+	Addr:      "127.0.0.1:47415",
+	GRPCAddr:  "127.0.0.1:47416",
+	RateLimit: 10,
+	CacheFor:  Duration(time.Second),
+}
+
+// Timings are the prayers of a day.
+type Timings struct {
+	Date     string
+	Location string
+	Hijri    HijriDate
+	Prayers  []StatusPrayer
+}
+
+// callTimeout is how long the servers wait for the scheduler.
+const callTimeout = 3 * time.Second
+
+// call runs f on the scheduler's goroutine and waits for it, giving up if
+// the scheduler is stuck, in which case f may still run later and what it
+// sets is not to be read. A panic in f, as a failed download, is returned.
+func call(s *Scheduler, f func()) error {
+	done := make(chan error, 1)
+	timeout := time.NewTimer(callTimeout)
+	defer timeout.Stop()
+	queued := s.TryDo(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("%v", r)
+			}
+		}()
+		f()
+		done <- nil
+	}, callTimeout)
+	if !queued {
+		return fmt.Errorf("scheduler not running")
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-timeout.C:
+		return fmt.Errorf("scheduler not running")
+	}
+}
+
+// serverCache has the responses of ServerStatus and ServerTimings.
+var serverCache = newResponseCache()
+
+// ServerStatus returns the Status now, from the scheduler's goroutine.
+func ServerStatus(s *Scheduler) (Status, error) {
+	v, err := serverCache.Get("status", func() (interface{}, error) {
+		var st Status
+		if err := call(s, func() { st = StatusAt(time.Now()) }); err != nil {
+			return nil, err
+		}
+		return st, nil
+	})
+	if err != nil {
+		return Status{}, err
+	}
+	return v.(Status), nil
+}
+
+// parseDate parses date as 2006-01-02, returning today when it's empty.
+func parseDate(date string) (time.Time, error) {
+	if date == "" {
+		return time.Now(), nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return day, fmt.Errorf("date %q is not like 2006-01-02", date)
+	}
+	return day, nil
+}
+
+// ServerTimings returns the prayers of day.
+func ServerTimings(s *Scheduler, day time.Time) (Timings, error) {
+	date := day.Format("2006-01-02")
+	v, err := serverCache.Get("timings "+date, func() (interface{}, error) {
+		t := Timings{Date: date}
+		err := call(s, func() {
+			t.Location = location.Name
+			t.Hijri = Hijri(location, day)
+			for _, p := range PrayerTimings(location, day) {
+				t.Prayers = append(t.Prayers, statusPrayer(p, time.Now()))
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		return t, nil
+	})
+	if err != nil {
+		return Timings{}, err
+	}
+	return v.(Timings), nil
+}
+
+// SubscribeEvents sends the events of topics, or all of them, to the
+// returned channel until unsubscribe is called. Events are dropped while
+// the channel is full rather than holding up the scheduler.
+func SubscribeEvents(s *Scheduler, topics []string) (events <-chan PluginMessage, unsubscribe func()) {
+	if len(topics) == 0 {
+		topics = busTopics
+	}
+	ch := make(chan PluginMessage, 64)
+	var unsubs []func()
+	for _, topic := range topics {
+		unsubs = append(unsubs, s.Bus.Subscribe(topic, func(ev BusEvent) {
+			select {
+			case ch <- pluginMessage(s, ev):
+			default:
+			}
+		}))
+	}
+	return ch, func() {
+		for _, unsub := range unsubs {
+			unsub()
+		}
+	}
+}
+
+// isLoopback reports whether host only takes connections from this machine.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// StartServer serves the JSON and gRPC APIs of s.
+func StartServer(s *Scheduler) {
+	if !server.Enabled {
+		return
+	}
+
+	config, err := serverTLS()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "server:", err)
+		return
+	}
+	for _, addr := range []string{server.Addr, server.GRPCAddr} {
+		if host, _, _ := net.SplitHostPort(addr); addr != "" && server.Token == "" && !isLoopback(host) {
+			fmt.Fprintf(os.Stderr, "server: %s is reachable from other machines without a Token\n", addr)
+		}
+	}
+
+	for _, topic := range []string{TimingsUpdated, LocationChanged} {
+		s.Bus.Subscribe(topic, func(BusEvent) { serverCache.Clear() })
+	}
+	limiter := newRateLimiter(server.RateLimit)
+
+	hs := &http.Server{Addr: server.Addr, Handler: limitRate(limiter, requireToken(requireRemoteToken(serverHandler(s)))), TLSConfig: config}
+	go func() {
+		var err error
+		if config != nil {
+			err = hs.ListenAndServeTLS("", "")
+		} else {
+			err = hs.ListenAndServe()
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "server:", err)
+		}
+	}()
+
+	if server.GRPCAddr == "" {
+		return
+	}
+	l, err := net.Listen("tcp", server.GRPCAddr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "server:", err)
+		return
+	}
+	g := grpc.NewServer(grpcServerOptions(config, limiter)...)
+	prayerpb.RegisterPrayerServer(g, &prayerServer{s: s})
+	go func() {
+		if err := g.Serve(l); err != nil {
+			fmt.Fprintln(os.Stderr, "server:", err)
+		}
+	}()
+}
+
+// serverHandler returns the handler of the JSON API of s, and its
+// OpenAPI document at /api/openapi.json.
+func serverHandler(s *Scheduler) http.Handler {
+	reply := func(w http.ResponseWriter, v interface{}, err error) {
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+
+	routes := []apiRoute{{
+		Path:    "/api/status",
+		Summary: "The schedule now",
+		Result:  Status{},
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			st, err := ServerStatus(s)
+			reply(w, st, err)
+		},
+	}, {
+		Path:    "/api/timings",
+		Summary: "The prayers of a day at the active location, or at lat and lon",
+		Params: []apiParam{
+			{Name: "date", Description: "The day as 2006-01-02, today when it's not set"},
+			{Name: "lat", Description: "The latitude of a place to compute the timings of", Type: "number"},
+			{Name: "lon", Description: "The longitude of the place", Type: "number"},
+			{Name: "elevation", Description: "The elevation of the place in meters", Type: "number"},
+			{Name: "method", Description: "The calculation method at the place, Method when it's not set", Type: "integer"},
+			{Name: "school", Description: "0 (Shafi) or 1 (Hanafi), School when it's not set", Type: "integer"},
+			{Name: "tz", Description: "The time zone of the place, like Europe/London, this machine's when it's not set"},
+			{Name: "name", Description: "The name of the place in the response"},
+		},
+		Result: Timings{},
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			if query := r.URL.Query(); query.Has("lat") || query.Has("lon") {
+				q, err := ParseTimingsQuery(query.Get)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				t, err := CoordsTimings(q, query.Get("date"))
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				reply(w, t, nil)
+				return
+			}
+			day, err := parseDate(r.URL.Query().Get("date"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			t, err := ServerTimings(s, day)
+			reply(w, t, err)
+		},
+	}, {
+		Path:    "/api/events",
+		Summary: "The events of the scheduler as server-sent events, each named after its topic",
+		Params:  []apiParam{{Name: "topic", Description: "A topic to stream, such as AdhanDue, all of them when it's not set", Repeated: true}},
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			flusher, ok := w.(http.Flusher)
+			if !ok {
+				http.Error(w, "streaming not supported", http.StatusInternalServerError)
+				return
+			}
+			events, unsubscribe := SubscribeEvents(s, r.URL.Query()["topic"])
+			defer unsubscribe()
+
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-store")
+			flusher.Flush()
+			for {
+				select {
+				case m := <-events:
+					data, err := json.Marshal(m)
+					if err != nil {
+						panic(err)
+					}
+					fmt.Fprintf(w, "event: %s\ndata: %s\n\n", m.Topic, data)
+					flusher.Flush()
+				case <-r.Context().Done():
+					return
+				}
+			}
+		},
+	}, {
+		Path:    "/api/announcements",
+		Summary: "The announcements of the signage display",
+		Result:  []Announcement{},
+		Body:    []Announcement{},
+		Handler: announcementsHandler(s),
+	}, {
+		Path:    "/api/health",
+		Summary: "How the server is doing",
+		Result:  Health{},
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			h, err := ServerHealth(s)
+			reply(w, h, err)
+		},
+	}, {
+		Path:    "/api/reload",
+		Summary: "Apply the config file again",
+		Method:  http.MethodPost,
+		Result:  Health{},
+		Handler: actionHandler(s, s.ReloadConfig),
+	}, {
+		Path:    "/api/restart",
+		Summary: "Compute the schedule again",
+		Method:  http.MethodPost,
+		Result:  Health{},
+		Handler: actionHandler(s, func() error {
+			s.Reload()
+			return nil
+		}),
+	}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/announcements", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(announcementsPage)
+	})
+	for _, r := range routes {
+		mux.HandleFunc(r.Path, r.Handler)
+	}
+	doc, err := json.MarshalIndent(OpenAPI(routes), "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	mux.HandleFunc("/api/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(doc)
+	})
+	if server.Debug {
+		handleDebug(mux)
+	}
+	return mux
+}
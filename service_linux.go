@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+const serviceName = "prayer.service"
+
+const unitTemplate = `[Unit]
+Description=Prayer times and adhan
+Wants=network-online.target
+After=network-online.target sound.target
+
+[Service]
+ExecStart=%q daemon
+WorkingDirectory=%s
+Restart=on-failure
+RestartSec=30
+
+[Install]
+WantedBy=default.target
+`
+
+func unitPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "systemd", "user", serviceName), nil
+}
+
+func systemctl(args ...string) error {
+	cmd := exec.Command("systemctl", append([]string{"--user"}, args...)...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+// InstallService writes a systemd user unit running the daemon from the
+// current directory, then enables and starts it.
+func InstallService() error {
+	cmd, err := autostartCommandLine()
+	if err != nil {
+		return err
+	}
+	exe, wd := cmd[0], cmd[len(cmd)-1]
+
+	path, err := unitPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(unitTemplate, exe, wd)), 0644); err != nil {
+		return err
+	}
+
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	return systemctl("enable", "--now", serviceName)
+}
+
+func UninstallService() error {
+	path, err := unitPath()
+	if err != nil {
+		return err
+	}
+
+	systemctl("disable", "--now", serviceName)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return systemctl("daemon-reload")
+}
+
+// serviceCommand is only used by the Windows service manager, systemd runs
+// the daemon command.
+func serviceCommand(args []string) int {
+	return daemonCommand(args)
+}
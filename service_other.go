@@ -0,0 +1,22 @@
+//go:build !linux && !windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+func InstallService() error {
+	return errors.New("not supported on this platform")
+}
+
+func UninstallService() error {
+	return errors.New("not supported on this platform")
+}
+
+func serviceCommand(args []string) int {
+	fmt.Fprintln(os.Stderr, usage)
+	return 2
+}
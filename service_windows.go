@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+const serviceName = "Prayer"
+
+// InstallService registers the scheduler as an automatically started
+// Windows service running from the current directory, with an event log
+// source for its notifications.
+func InstallService() error {
+	cmd, err := autostartCommandLine()
+	if err != nil {
+		return err
+	}
+	exe, wd := cmd[0], cmd[len(cmd)-1]
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(serviceName); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s already exists", serviceName)
+	}
+
+	s, err := m.CreateService(serviceName, exe, mgr.Config{
+		DisplayName: "Prayer times",
+		Description: "Plays the adhan and prayer reminders.",
+		StartType:   mgr.StartAutomatic,
+	}, "service", "-dir", wd)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	err = eventlog.InstallAsEventCreate(serviceName, eventlog.Error|eventlog.Warning|eventlog.Info)
+	if err != nil {
+		s.Delete()
+		return err
+	}
+	return s.Start()
+}
+
+func UninstallService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", serviceName)
+	}
+	defer s.Close()
+
+	s.Control(svc.Stop)
+	if err := s.Delete(); err != nil {
+		return err
+	}
+	return eventlog.Remove(serviceName)
+}
+
+// serviceCommand is run by the service control manager.
+func serviceCommand(args []string) int {
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == "-dir" {
+			if err := os.Chdir(args[i+1]); err != nil {
+				return 1
+			}
+		}
+	}
+
+	if err := svc.Run(serviceName, prayerService{}); err != nil {
+		return 1
+	}
+	return 0
+}
+
+type prayerService struct{}
+
+func (prayerService) Execute(args []string, r <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	changes <- svc.Status{State: svc.StartPending}
+
+	elog, err := eventlog.Open(serviceName)
+	if err != nil {
+		return true, 1
+	}
+	defer elog.Close()
+
+	defer func() {
+		if err := recover(); err != nil {
+			elog.Error(1, fmt.Sprint("Prayer stopped: ", err))
+			panic(err)
+		}
+	}()
+
+	if err := LoadConfig(); err != nil {
+		elog.Error(1, err.Error())
+		return true, 1
+	}
+	OpenLog()
+	RestoreProfile()
+
+	sched := NewScheduler()
+	StartIntegrations(sched)
+	sched.Notify = func(title, message string) {
+		elog.Info(1, title+": "+message)
+	}
+	elog.Info(1, fmt.Sprintf("Next prayer is %s at %s", sched.Next.Label(), sched.Next.Time.Format("15:04")))
+
+	changes <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	tick := time.Tick(time.Second)
+	for {
+		select {
+		case now := <-tick:
+			sched.Tick(now)
+		case c := <-r:
+			switch c.Cmd {
+			case svc.Interrogate:
+				changes <- c.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				changes <- svc.Status{State: svc.StopPending}
+				sched.Shutdown()
+				return false, 0
+			}
+		}
+	}
+}
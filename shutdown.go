@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// soundsPlaying are the sounds playing, which mark themselves played in
+// the log when they end.
+var soundsPlaying sync.WaitGroup
+
+// shutdownWait is how long Shutdown waits for the sounds to mark
+// themselves played.
+const shutdownWait = 2 * time.Second
+
+// ShutdownSignals returns a channel receiving SIGINT and SIGTERM, for the
+// frontends to shut down on them rather than being killed mid-write.
+func ShutdownSignals() <-chan os.Signal {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	return ch
+}
+
+// savedSnooze is the snoozed reminder kept across restarts.
+type savedSnooze struct {
+	Reminder Alert
+	Snoozes  int
+	Until    time.Time
+	Next     time.Time // of the prayer reminded of
+}
+
+// Shutdown stops the sounds, saves the snoozed reminder for the next start
+// and closes the log once the sounds playing have been marked played. s
+// can't be used after.
+func (s *Scheduler) Shutdown() {
+	StopSound()
+	clearToasts()
+	var saved []byte
+	if !s.snoozed.IsZero() {
+		saved, _ = json.Marshal(savedSnooze{s.reminder, s.snoozes, s.snoozed, s.Next.Time})
+	}
+	SetState("snooze", string(saved))
+
+	done := make(chan bool)
+	go func() {
+		soundsPlaying.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(shutdownWait):
+	}
+	CloseLog()
+}
+
+// restoreSnooze snoozes the reminder saved by Shutdown again, if its
+// prayer is still to come.
+func (s *Scheduler) restoreSnooze() {
+	var saved savedSnooze
+	if err := json.Unmarshal([]byte(State("snooze")), &saved); err != nil {
+		return
+	}
+	if saved.Reminder.Name == s.Next.Name && saved.Next.Equal(s.Next.Time) {
+		s.reminder, s.snoozes, s.snoozed = saved.Reminder, saved.Snoozes, saved.Until
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Signage mode shows the schedule fullscreen on a TV at a mosque: a large
+// clock, the adhan and iqama times of the day, the Hijri date and the
+// Announcements of the day scrolling along the bottom, which can also be
+// edited at /announcements in server mode. From each adhan it counts down
+// to the iqama, then shows SilenceText for the PrayerLength of the prayer.
+// With Dim, the display dims between prayers, from DimAfter past an iqama
+// until DimBefore the next adhan. The Theme sets its colors, font,
+// background image and layout.
+var signage = struct {
+	Announcements []Announcement
+	PrayerLength  Duration
+	SilenceText   string
+	Dim           bool
+	DimAfter      Duration
+	DimBefore     Duration
+}{
+	PrayerLength: Duration(10 * time.Minute),
+	SilenceText:  "Please silence your phones",
+	Dim:          true,
+	DimAfter:     Duration(30 * time.Minute),
+	DimBefore:    Duration(15 * time.Minute),
+}
+
+// iqama is how long after the adhan of each prayer the iqama is.
+var iqama = map[string]Duration{
+	"Fajr":    Duration(20 * time.Minute),
+	"Dhuhr":   Duration(15 * time.Minute),
+	"Asr":     Duration(10 * time.Minute),
+	"Maghrib": Duration(5 * time.Minute),
+	"Isha":    Duration(10 * time.Minute),
+}
+
+// IqamaTime returns the time of the iqama of p.
+func IqamaTime(p Prayer) time.Time {
+	return p.Time.Add(time.Duration(iqama[p.Name]))
+}
+
+// SignageRow is a prayer in the timetable of signage mode.
+type SignageRow struct {
+	Label, Adhan, Iqama string
+}
+
+// SignageRows returns the timetable of prayers.
+func SignageRows(prayers Prayers) []SignageRow {
+	var rows []SignageRow
+	for _, p := range prayers {
+		rows = append(rows, SignageRow{
+			Label: p.Label(),
+			Adhan: p.Time.Format(timeLayout("03:04")),
+			Iqama: IqamaTime(p).Format(timeLayout("03:04")),
+		})
+	}
+	return rows
+}
+
+// Screens of the signage display.
+const (
+	TimetableScreen = iota
+	IqamaScreen     // counting down from the adhan to the iqama
+	SilenceScreen   // during the prayer
+)
+
+// SignageScreen returns the screen shown at now and the prayer it's of,
+// following the adhan and iqama of prayers.
+func SignageScreen(prayers Prayers, now time.Time) (int, Prayer) {
+	for _, p := range prayers {
+		iqama := IqamaTime(p)
+		switch {
+		case now.Before(p.Time):
+		case now.Before(iqama):
+			return IqamaScreen, p
+		case now.Before(iqama.Add(time.Duration(signage.PrayerLength))):
+			return SilenceScreen, p
+		}
+	}
+	return TimetableScreen, Prayer{}
+}
+
+// SignageDimmed reports whether the display is dimmed at now, between the
+// iqama of the prayers and the adhan of next.
+func SignageDimmed(prayers Prayers, next Prayer, now time.Time) bool {
+	if !signage.Dim || next.Time.Sub(now) < time.Duration(signage.DimBefore) {
+		return false
+	}
+	for _, p := range prayers {
+		if !now.Before(p.Time) && now.Sub(IqamaTime(p)) < time.Duration(signage.DimAfter) {
+			return false
+		}
+	}
+	return true
+}
+
+// SignageDate returns the Gregorian and Hijri dates of now.
+func SignageDate(now time.Time) string {
+	h := Hijri(location, now)
+	return fmt.Sprintf("%s  ·  %d %s %d", now.Format("Monday 2 January 2006"), h.Day, h.MonthName, h.Year)
+}
+
+// tickerSeparator is between the announcements in the ticker.
+const tickerSeparator = "   •   "
+
+// TickerText returns width characters of the announcements scrolled by
+// step characters, going round.
+func TickerText(announcements []string, width, step int) string {
+	if len(announcements) == 0 {
+		return ""
+	}
+	text := []rune(strings.Join(announcements, tickerSeparator) + tickerSeparator)
+	if len(text) < width {
+		// Short enough to stand still.
+		return strings.Join(announcements, tickerSeparator)
+	}
+	out := make([]rune, width)
+	for i := range out {
+		out[i] = text[(step+i)%len(text)]
+	}
+	return string(out)
+}
+
+// signageCommand runs the scheduler with the signage display instead of
+// the tray app.
+func signageCommand(args []string) int {
+	fs := flag.NewFlagSet("signage", flag.ExitOnError)
+	dir := fs.String("dir", "", "directory with the sounds and timings")
+	fs.Parse(args)
+
+	if *dir != "" {
+		if err := os.Chdir(*dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	if _, ok := SingleInstance(); !ok {
+		fmt.Fprintln(os.Stderr, "Prayer is already running")
+		return 1
+	}
+
+	if err := LoadConfig(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	OpenLog()
+	RestoreProfile()
+
+	sched := NewScheduler()
+	StartIntegrations(sched)
+	return signageMain(sched)
+}
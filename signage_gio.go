@@ -0,0 +1,14 @@
+//go:build gio
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// signageMain is only in the IUP frontend.
+func signageMain(sched *Scheduler) int {
+	fmt.Fprintln(os.Stderr, "signage: not available in the Gio build")
+	return 1
+}
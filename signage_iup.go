@@ -0,0 +1,236 @@
+//go:build !gio
+
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gen2brain/iup-go/iup"
+)
+
+// Colors of the signage display, lit and dimmed, unless the theme sets them.
+var (
+	signageFG    = color.RGBA{255, 255, 255, 255}
+	signageDimFG = color.RGBA{70, 70, 70, 255}
+	signageBG    = color.RGBA{0, 0, 0, 255}
+)
+
+// iupColor returns c as an IUP color attribute.
+func iupColor(c color.RGBA) string {
+	return fmt.Sprintf("%d %d %d", c.R, c.G, c.B)
+}
+
+// signageMain shows the signage display until Ctrl+Q is pressed. Esc stops
+// the sound playing.
+func signageMain(sched *Scheduler) int {
+	iup.Open()
+	defer iup.Close()
+
+	theme, err := LoadTheme(themePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "signage:", err)
+	}
+	lit := iupColor(themeColor(theme.Foreground, signageFG))
+	dimmed := iupColor(themeColor(theme.Dimmed, signageDimFG))
+	face := theme.Font
+	if face == "" {
+		face = "Courier"
+	}
+
+	screenW, screenH := 0, 0
+	fmt.Sscanf(iup.GetGlobal("SCREENSIZE"), "%dx%d", &screenW, &screenH)
+
+	initScale()
+	iup.SetGlobal("DEFAULTFONT", face+" "+scaled(15))
+
+	var labels []iup.Ihandle
+	label := func(size int) iup.Ihandle {
+		l := iup.Label("")
+		l.SetAttributes(map[string]string{
+			"FONTSIZE":  scaled(size),
+			"ALIGNMENT": "ACENTER",
+			"EXPAND":    "HORIZONTAL",
+		})
+		labels = append(labels, l)
+		return l
+	}
+
+	clock := label(120)
+	date := label(28)
+	next := label(36)
+
+	// The timetable, a row of labels for each prayer under the headings,
+	// or with the columns layout a column for each beside them.
+	headings := [3]iup.Ihandle{label(32), label(32), label(32)}
+	setTitle(headings[0], "")
+	setTitle(headings[1], "Adhan")
+	setTitle(headings[2], "Iqama")
+	grid := [][3]iup.Ihandle{headings}
+	rows := make([][3]iup.Ihandle, len(sched.Prayers))
+	for i := range rows {
+		for j := range rows[i] {
+			rows[i][j] = label(44)
+		}
+		grid = append(grid, rows[i])
+	}
+	var cells []iup.Ihandle
+	numDiv := 3
+	if theme.Layout == "columns" {
+		numDiv = len(grid)
+		for j := 0; j < 3; j++ {
+			for i := range grid {
+				cells = append(cells, grid[i][j])
+			}
+		}
+	} else {
+		for i := range grid {
+			cells = append(cells, grid[i][:]...)
+		}
+	}
+	table := iup.GridBox(cells...)
+	table.SetAttributes(map[string]string{
+		"NUMDIV":         strconv.Itoa(numDiv),
+		"ALIGNMENTLIN":   "ACENTER",
+		"GAPLIN":         scaled(12),
+		"GAPCOL":         scaled(60),
+		"HOMOGENEOUSCOL": "YES",
+	})
+	updateTimings := func() {
+		for i, r := range SignageRows(sched.Prayers) {
+			setTitle(rows[i][0], r.Label)
+			setTitle(rows[i][1], r.Adhan)
+			setTitle(rows[i][2], r.Iqama)
+		}
+	}
+	updateTimings()
+	sched.Bus.Subscribe(TimingsUpdated, func(BusEvent) { updateTimings() })
+
+	// After the adhan, the countdown to the iqama and then the reminder to
+	// silence phones replace the timetable.
+	iqamaTitle := label(60)
+	iqamaCountdown := label(200)
+	silence := label(80)
+	screens := iup.Zbox(
+		iup.Vbox(clock, date, next, iup.Fill(), table, iup.Fill()),
+		iup.Vbox(iup.Fill(), iqamaTitle, iqamaCountdown, iup.Fill()),
+		iup.Vbox(iup.Fill(), silence, iup.Fill()),
+	)
+	iup.SetAttribute(screens, "ALIGNMENT", "ACENTER")
+
+	ticker := label(30)
+
+	vbox := iup.Vbox(screens, ticker)
+	vbox.SetAttributes(map[string]string{
+		"ALIGNMENT": "ACENTER",
+		"MARGIN":    pxSize(20, 20),
+		"GAP":       scaled(10),
+	})
+	dlg := iup.Dialog(vbox)
+	dlg.SetAttributes(map[string]string{
+		"TITLE":      "Prayer",
+		"FULLSCREEN": "YES",
+		"BGCOLOR":    iupColor(themeColor(theme.Background, signageBG)),
+		"FGCOLOR":    lit,
+	})
+	// The theme's image is stretched over the screen, behind the text.
+	if img, err := theme.Image(); err != nil {
+		fmt.Fprintln(os.Stderr, "signage:", err)
+	} else if img != nil && screenW > 0 && screenH > 0 {
+		iup.ImageFromImage(ResizeImage(img, screenW, screenH)).SetHandle("signagebackground")
+		iup.SetAttribute(dlg, "BACKGROUND", "signagebackground")
+	}
+	iup.SetCallback(dlg, "K_ANY", iup.KAnyFunc(func(ih iup.Ihandle, c int) int {
+		switch c {
+		case iup.K_ESC:
+			StopSound()
+			sched.Dismiss()
+		case iup.XKeyCtrl(iup.K_Q):
+			return iup.CLOSE
+		default:
+			return iup.CONTINUE
+		}
+		return iup.IGNORE
+	}))
+
+	sched.Flash = func() { flashDialog(dlg) }
+
+	configChanged := make(chan bool, 1)
+	if configReload {
+		go watchConfig(configChanged)
+	}
+
+	signals := ShutdownSignals()
+	fg := lit
+	timer := iup.Timer()
+	iup.SetAttribute(timer, "TIME", 1000)
+	iup.SetCallback(timer, "ACTION_CB", iup.TimerActionFunc(func(ih iup.Ihandle) int {
+		defer sched.Recover("timer")
+		select {
+		case <-signals:
+			return iup.CLOSE
+		case <-configChanged:
+			if err := sched.ReloadConfig(); err != nil {
+				sched.Notify("Config not applied", err.Error())
+			}
+		default:
+		}
+
+		now := time.Now()
+		sched.Tick(now)
+		setTitle(clock, now.Format("3:04"))
+		setTitle(date, SignageDate(now))
+		upcoming := sched.Upcoming()
+		setTitle(next, fmt.Sprintf("%s %s", upcoming.Label(), FormatUntil("in", upcoming.Time)))
+
+		screen, p := SignageScreen(sched.Today, now)
+		switch screen {
+		case IqamaScreen:
+			setTitle(iqamaTitle, "Iqama of "+p.Label()+" in")
+			setTitle(iqamaCountdown, FormatRemaining(IqamaTime(p).Sub(now).Round(time.Second)))
+		case SilenceScreen:
+			setTitle(silence, signage.SilenceText)
+		}
+		if pos := strconv.Itoa(screen); iup.GetAttribute(screens, "VALUEPOS") != pos {
+			iup.SetAttribute(screens, "VALUEPOS", pos)
+		}
+
+		want := lit
+		if SignageDimmed(sched.Today, upcoming, now) {
+			want = dimmed
+		}
+		if want != fg {
+			fg = want
+			for _, l := range labels {
+				iup.SetAttribute(l, "FGCOLOR", fg)
+			}
+		}
+		return iup.DEFAULT
+	}))
+
+	// The ticker moves a character at a time, as wide as the screen.
+	width := 60
+	if screenW > 0 {
+		// Courier is about 0.6em wide, at 96 pixels an inch, and other
+		// fonts about as wide on average.
+		width = int(float64(screenW) / (float64(px(30)) * 96 / 72 * 0.6))
+	}
+	step := 0
+	tickerTimer := iup.Timer()
+	iup.SetAttribute(tickerTimer, "TIME", 200)
+	iup.SetCallback(tickerTimer, "ACTION_CB", iup.TimerActionFunc(func(ih iup.Ihandle) int {
+		setTitle(ticker, TickerText(ShownAnnouncements(time.Now()), width, step))
+		step++
+		return iup.DEFAULT
+	}))
+
+	iup.Show(dlg)
+	iup.SetAttribute(timer, "RUN", "YES")
+	iup.SetAttribute(tickerTimer, "RUN", "YES")
+	code := iup.MainLoop()
+	sched.Shutdown()
+	return code
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/prop"
+)
+
+const (
+	sniPath  = "/StatusNotifierItem"
+	menuPath = "/MenuBar"
+)
+
+// SNITray is a StatusNotifierItem tray icon with a dbusmenu menu, for
+// desktops like GNOME on Wayland where IUP's tray icon doesn't show.
+type SNITray struct {
+	Activated <-chan bool   // the icon was clicked
+	Clicked   <-chan int    // index of the menu item clicked
+	Action    <-chan string // action of a notification invoked
+
+	conn      *dbus.Conn
+	name      string
+	activated chan bool
+	clicked   chan int
+	action    chan string
+
+	mu            sync.Mutex
+	labels        []string
+	revision      uint32
+	notifications map[uint32]bool // ids of the notifications with actions
+}
+
+// useSNITray reports whether the tray should use StatusNotifierItem rather
+// than IUP: on Wayland, when a StatusNotifierWatcher is running.
+func useSNITray() bool {
+	if !sniTray || os.Getenv("WAYLAND_DISPLAY") == "" {
+		return false
+	}
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return false
+	}
+	var has bool
+	conn.BusObject().Call("org.freedesktop.DBus.NameHasOwner", 0, "org.kde.StatusNotifierWatcher").Store(&has)
+	return has
+}
+
+// trayStatus returns how the tray icon will show, or why it won't.
+func trayStatus() (string, error) {
+	switch {
+	case useSNITray():
+		return "StatusNotifierItem, on Wayland", nil
+	case os.Getenv("WAYLAND_DISPLAY") != "" && !sniTray:
+		return "", errors.New("IUP's icon doesn't show on Wayland\n      turn SNITray on")
+	case os.Getenv("WAYLAND_DISPLAY") != "":
+		return "", errors.New("no StatusNotifierWatcher is running, so the icon won't show on Wayland\n      on GNOME, install the AppIndicator extension")
+	case os.Getenv("DISPLAY") != "":
+		return "IUP, on X11", nil
+	}
+	return "", errors.New("no display\n      run prayer daemon without a tray instead")
+}
+
+// StartSNITray registers a tray icon titled title showing icon.
+func StartSNITray(title string, icon image.Image) (*SNITray, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &SNITray{
+		conn:      conn,
+		activated: make(chan bool, 1),
+		clicked:   make(chan int, 1),
+		action:    make(chan string, 1),
+
+		notifications: make(map[uint32]bool),
+	}
+	t.Activated, t.Clicked, t.Action = t.activated, t.clicked, t.action
+
+	if err := conn.Export(sniItem{t}, sniPath, "org.kde.StatusNotifierItem"); err != nil {
+		return nil, err
+	}
+	_, err = prop.Export(conn, sniPath, prop.Map{
+		"org.kde.StatusNotifierItem": {
+			"Category":   {Value: "ApplicationStatus"},
+			"Id":         {Value: "prayer"},
+			"Title":      {Value: title},
+			"Status":     {Value: "Active"},
+			"IconName":   {Value: ""},
+			"IconPixmap": {Value: pixmaps(icon)},
+			"ItemIsMenu": {Value: false},
+			"Menu":       {Value: dbus.ObjectPath(menuPath)},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Export(dbusMenu{t}, menuPath, "com.canonical.dbusmenu"); err != nil {
+		return nil, err
+	}
+	_, err = prop.Export(conn, menuPath, prop.Map{
+		"com.canonical.dbusmenu": {
+			"Version":       {Value: uint32(3)},
+			"TextDirection": {Value: "ltr"},
+			"Status":        {Value: "normal"},
+			"IconThemePath": {Value: []string{}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("org.kde.StatusNotifierItem-%d-1", os.Getpid())
+	reply, err := conn.RequestName(name, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		return nil, err
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		return nil, errors.New("sni: name " + name + " taken")
+	}
+	t.name = name
+
+	if err := conn.AddMatchSignal(
+		dbus.WithMatchInterface("org.freedesktop.Notifications"),
+		dbus.WithMatchMember("ActionInvoked"),
+	); err != nil {
+		return nil, err
+	}
+	signals := make(chan *dbus.Signal, 4)
+	conn.Signal(signals)
+	go t.actions(signals)
+
+	watcher := conn.Object("org.kde.StatusNotifierWatcher", "/StatusNotifierWatcher")
+	if err := watcher.Call("org.kde.StatusNotifierWatcher.RegisterStatusNotifierItem", 0, name).Err; err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Close removes the tray icon.
+func (t *SNITray) Close() {
+	t.conn.ReleaseName(t.name)
+}
+
+// SetMenu replaces the menu items, an empty label being a separator.
+func (t *SNITray) SetMenu(labels []string) {
+	t.mu.Lock()
+	t.labels = labels
+	t.revision++
+	rev := t.revision
+	t.mu.Unlock()
+
+	t.conn.Emit(menuPath, "com.canonical.dbusmenu.LayoutUpdated", rev, int32(0))
+}
+
+// Notify shows a desktop notification, SNI having no balloons. actions are
+// pairs of an action sent on Action when chosen and its button label.
+func (t *SNITray) Notify(title, text string, actions ...string) {
+	n := t.conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
+	var id uint32
+	err := n.Call("org.freedesktop.Notifications.Notify", 0, "Prayer", uint32(0), "", title, text,
+		append([]string{}, actions...), map[string]dbus.Variant{}, int32(-1)).Store(&id)
+	if err == nil && len(actions) > 0 {
+		t.mu.Lock()
+		t.notifications[id] = true
+		t.mu.Unlock()
+	}
+}
+
+func (t *SNITray) actions(signals <-chan *dbus.Signal) {
+	for sig := range signals {
+		if sig.Name != "org.freedesktop.Notifications.ActionInvoked" || len(sig.Body) < 2 {
+			continue
+		}
+		id, _ := sig.Body[0].(uint32)
+		action, _ := sig.Body[1].(string)
+
+		t.mu.Lock()
+		ours := t.notifications[id]
+		delete(t.notifications, id)
+		t.mu.Unlock()
+
+		if ours {
+			select {
+			case t.action <- action:
+			default:
+			}
+		}
+	}
+}
+
+type sniPixmap struct {
+	Width, Height int32
+	ARGB          []byte
+}
+
+// pixmaps renders img at each of iconSizes, so the tray host picks the one
+// fitting its scale.
+func pixmaps(img image.Image) []sniPixmap {
+	var p []sniPixmap
+	for _, s := range iconSizes {
+		p = append(p, pixmap(ResizeIcon(img, s)))
+	}
+	return p
+}
+
+// pixmap converts img to the ARGB32, network byte order, pixels of SNI.
+func pixmap(img image.Image) sniPixmap {
+	b := img.Bounds()
+	p := sniPixmap{Width: int32(b.Dx()), Height: int32(b.Dy())}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, a := img.At(x, y).RGBA()
+			p.ARGB = append(p.ARGB, byte(a>>8), byte(r>>8), byte(g>>8), byte(bl>>8))
+		}
+	}
+	return p
+}
+
+// sniItem has the org.kde.StatusNotifierItem methods.
+type sniItem struct{ t *SNITray }
+
+func (s sniItem) Activate(x, y int32) *dbus.Error {
+	select {
+	case s.t.activated <- true:
+	default:
+	}
+	return nil
+}
+
+func (s sniItem) SecondaryActivate(x, y int32) *dbus.Error { return s.Activate(x, y) }
+func (s sniItem) ContextMenu(x, y int32) *dbus.Error       { return nil }
+func (s sniItem) Scroll(delta int32, orientation string) *dbus.Error {
+	return nil
+}
+
+// menuLayout is a dbusmenu item, with Children holding more menuLayouts.
+type menuLayout struct {
+	ID       int32
+	Props    map[string]dbus.Variant
+	Children []dbus.Variant
+}
+
+type menuProps struct {
+	ID    int32
+	Props map[string]dbus.Variant
+}
+
+type menuEvent struct {
+	ID        int32
+	EventID   string
+	Data      dbus.Variant
+	Timestamp uint32
+}
+
+// dbusMenu has the com.canonical.dbusmenu methods. Item 0 is the root and
+// item i the label i-1.
+type dbusMenu struct{ t *SNITray }
+
+func (m dbusMenu) props(id int32) map[string]dbus.Variant {
+	if id == 0 {
+		return map[string]dbus.Variant{"children-display": dbus.MakeVariant("submenu")}
+	}
+	if m.t.labels[id-1] == "" {
+		return map[string]dbus.Variant{"type": dbus.MakeVariant("separator")}
+	}
+	return map[string]dbus.Variant{"label": dbus.MakeVariant(m.t.labels[id-1])}
+}
+
+func (m dbusMenu) GetLayout(parent, depth int32, names []string) (uint32, menuLayout, *dbus.Error) {
+	m.t.mu.Lock()
+	defer m.t.mu.Unlock()
+
+	root := menuLayout{ID: parent, Props: m.props(parent), Children: []dbus.Variant{}}
+	if parent == 0 && depth != 0 {
+		for i := range m.t.labels {
+			id := int32(i + 1)
+			root.Children = append(root.Children, dbus.MakeVariant(menuLayout{id, m.props(id), []dbus.Variant{}}))
+		}
+	}
+	return m.t.revision, root, nil
+}
+
+func (m dbusMenu) GetGroupProperties(ids []int32, names []string) ([]menuProps, *dbus.Error) {
+	m.t.mu.Lock()
+	defer m.t.mu.Unlock()
+
+	var props []menuProps
+	for _, id := range ids {
+		if id >= 0 && int(id) <= len(m.t.labels) {
+			props = append(props, menuProps{id, m.props(id)})
+		}
+	}
+	return props, nil
+}
+
+func (m dbusMenu) GetProperty(id int32, name string) (dbus.Variant, *dbus.Error) {
+	m.t.mu.Lock()
+	defer m.t.mu.Unlock()
+
+	if id < 0 || int(id) > len(m.t.labels) {
+		return dbus.Variant{}, dbus.MakeFailedError(errors.New("no such item"))
+	}
+	return m.props(id)[name], nil
+}
+
+func (m dbusMenu) Event(id int32, eventID string, data dbus.Variant, timestamp uint32) *dbus.Error {
+	if eventID == "clicked" && id > 0 {
+		select {
+		case m.t.clicked <- int(id - 1):
+		default:
+		}
+	}
+	return nil
+}
+
+func (m dbusMenu) EventGroup(events []menuEvent) ([]int32, *dbus.Error) {
+	for _, e := range events {
+		m.Event(e.ID, e.EventID, e.Data, e.Timestamp)
+	}
+	return []int32{}, nil
+}
+
+func (m dbusMenu) AboutToShow(id int32) (bool, *dbus.Error) { return false, nil }
+
+func (m dbusMenu) AboutToShowGroup(ids []int32) ([]int32, []int32, *dbus.Error) {
+	return []int32{}, []int32{}, nil
+}
@@ -0,0 +1,26 @@
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+	"image"
+)
+
+type SNITray struct {
+	Activated <-chan bool
+	Clicked   <-chan int
+	Action    <-chan string
+}
+
+func useSNITray() bool { return false }
+
+func trayStatus() (string, error) { return "the system tray", nil }
+
+func StartSNITray(title string, icon image.Image) (*SNITray, error) {
+	return nil, errors.New("StatusNotifierItem is only supported on Linux")
+}
+
+func (t *SNITray) Close()                                       {}
+func (t *SNITray) SetMenu(labels []string)                      {}
+func (t *SNITray) Notify(title, text string, actions ...string) {}
@@ -0,0 +1,17 @@
+package main
+
+// TestSound plays path as the alerts do, for the settings to try a sound
+// before relying on it at Fajr. It returns at once, the sound playing until
+// it ends or StopSound, or an error if path can't be decoded or there's no
+// audio device.
+func TestSound(path string) error {
+	streamer, err := openSound(path)
+	if err != nil {
+		return err
+	}
+	go func() {
+		defer streamer.Close()
+		play(streamer)
+	}()
+	return nil
+}
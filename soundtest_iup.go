@@ -0,0 +1,56 @@
+//go:build !gio
+
+package main
+
+import "github.com/gen2brain/iup-go/iup"
+
+// testButton returns a button playing the sound path returns, showing
+// why it can't be played on errorLabel.
+func testButton(path func() string, errorLabel iup.Ihandle) iup.Ihandle {
+	b := iup.Button("Test")
+	iup.SetAttribute(b, "TIP", "Play the sound as an alert would")
+	iup.SetCallback(b, "ACTION", iup.ActionFunc(func(ih iup.Ihandle) int {
+		StopSound()
+		if err := TestSound(path()); err != nil {
+			setTitle(errorLabel, err.Error())
+		} else {
+			setTitle(errorLabel, "")
+		}
+		return iup.DEFAULT
+	}))
+	return b
+}
+
+// stopButton returns a button stopping the sounds played.
+func stopButton() iup.Ihandle {
+	b := iup.Button("Stop")
+	iup.SetCallback(b, "ACTION", iup.ActionFunc(func(ih iup.Ihandle) int {
+		StopSound()
+		return iup.DEFAULT
+	}))
+	return b
+}
+
+// soundsFrame lists the sounds of the settings, each with a button to test
+// it.
+func soundsFrame() iup.Ihandle {
+	errorLabel := iup.Label("")
+	errorLabel.SetAttributes(map[string]string{"FGCOLOR": "200 0 0", "EXPAND": "HORIZONTAL"})
+
+	var rows []iup.Ihandle
+	for _, path := range soundFiles() {
+		path := path
+		rows = append(rows, iup.Label(path), testButton(func() string { return path }, errorLabel))
+	}
+	grid := iup.GridBox(rows...)
+	grid.SetAttributes(map[string]string{
+		"NUMDIV":       "2",
+		"ALIGNMENTLIN": "ACENTER",
+		"GAPLIN":       scaled(4),
+		"GAPCOL":       scaled(4),
+	})
+
+	frame := iup.Frame(iup.Vbox(grid, errorLabel, stopButton()))
+	iup.SetAttribute(frame, "TITLE", "Sounds")
+	return frame
+}
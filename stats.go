@@ -0,0 +1,97 @@
+package main
+
+import "time"
+
+type Stats struct {
+	Streak     int     // days in a row with all five prayers
+	Week       float64 // on-time percentage over the last 7 days
+	Month      float64 // on-time percentage over the last 30 days
+	MostMissed string  // over the last 30 days, empty if none missed
+	ByPrayer   map[string]float64
+}
+
+// PrayerStats computes statistics over the prayer log. Periods end
+// yesterday, so prayers still due today don't count as missed, and start no
+// earlier than the first logged day.
+func PrayerStats(now time.Time) Stats {
+	stats := Stats{ByPrayer: make(map[string]float64, len(prayerNames))}
+	today := now.Format(time.DateOnly)
+
+	var first string
+	err := db.QueryRow(`SELECT COALESCE(MIN(date), '') FROM prayers`).Scan(&first)
+	if err != nil {
+		panic(err)
+	}
+	if first == "" {
+		return stats
+	}
+
+	// Streak of complete days, counting today only once it's complete.
+	rows, err := db.Query(`SELECT date FROM prayers GROUP BY date
+		HAVING COUNT(*) = ? ORDER BY date DESC`, len(prayerNames))
+	if err != nil {
+		panic(err)
+	}
+	day := now
+	for rows.Next() {
+		var date string
+		rows.Scan(&date)
+		if date == today {
+			stats.Streak++
+			continue
+		}
+		day = day.AddDate(0, 0, -1)
+		if date != day.Format(time.DateOnly) {
+			break
+		}
+		stats.Streak++
+	}
+	rows.Close()
+
+	onTime := func(days int, prayer string) (n, expected int) {
+		from := now.AddDate(0, 0, -days).Format(time.DateOnly)
+		if from < first {
+			from = first
+		}
+		query := `SELECT COUNT(*) FROM prayers WHERE on_time AND date >= ? AND date < ?`
+		args := []interface{}{from, today}
+		if prayer != "" {
+			query += ` AND prayer = ?`
+			args = append(args, prayer)
+		}
+		if err := db.QueryRow(query, args...).Scan(&n); err != nil {
+			panic(err)
+		}
+
+		fromDay, _ := time.ParseInLocation(time.DateOnly, from, now.Location())
+		todayDay, _ := time.ParseInLocation(time.DateOnly, today, now.Location())
+		expected = int(todayDay.Sub(fromDay).Hours()/24 + 0.5)
+		if prayer == "" {
+			expected *= len(prayerNames)
+		}
+		return n, expected
+	}
+	percentage := func(n, expected int) float64 {
+		if expected == 0 {
+			return 0
+		}
+		return 100 * float64(n) / float64(expected)
+	}
+
+	stats.Week = percentage(onTime(7, ""))
+	stats.Month = percentage(onTime(30, ""))
+
+	leastPrayed := 100.0
+	for _, name := range prayerNames {
+		p := percentage(onTime(30, name))
+		stats.ByPrayer[name] = p
+		if p < leastPrayed {
+			leastPrayed = p
+			stats.MostMissed = name
+		}
+	}
+	if leastPrayed == 100 {
+		stats.MostMissed = ""
+	}
+	return stats
+}
@@ -0,0 +1,139 @@
+//go:build !gio
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gen2brain/iup-go/iup"
+)
+
+var statsDialog iup.Ihandle
+
+// showStats shows the prayer log statistics with a per-prayer bar chart of
+// the monthly on-time percentage.
+func showStats() {
+	if statsDialog != 0 {
+		iup.Show(statsDialog)
+		return
+	}
+
+	var stats Stats
+
+	summary := iup.Label("")
+	iup.SetAttribute(summary, "EXPAND", "HORIZONTAL")
+
+	chart := iup.Canvas()
+	iup.SetAttribute(chart, "RASTERSIZE", pxSize(400, 200))
+	iup.SetAttribute(chart, "BORDER", "NO")
+	iup.SetCallback(chart, "ACTION", iup.CanvasActionFunc(func(ih iup.Ihandle, posx, posy float64) int {
+		iup.DrawBegin(ih)
+		defer iup.DrawEnd(ih)
+
+		iup.DrawParentBackground(ih)
+
+		w, h := iup.DrawGetSize(ih)
+		_, th := iup.DrawGetTextSize(ih, "Fajr")
+		barWidth := w / len(prayerNames)
+		maxHeight := h - 2*th - px(4)
+
+		for i, name := range prayerNames {
+			x := i * barWidth
+			barHeight := int(float64(maxHeight) * stats.ByPrayer[name] / 100)
+
+			iup.SetAttribute(ih, "DRAWCOLOR", "0 128 0")
+			iup.SetAttribute(ih, "DRAWSTYLE", "FILL")
+			iup.DrawRectangle(ih, x+px(8), h-th-px(2)-barHeight, x+barWidth-px(8), h-th-px(2))
+
+			iup.SetAttribute(ih, "DRAWCOLOR", "0 0 0")
+			label := PrayerLabel(name)
+			tw, _ := iup.DrawGetTextSize(ih, label)
+			iup.DrawText(ih, label, x+(barWidth-tw)/2, h-th, -1, -1)
+
+			pct := fmt.Sprintf("%.0f%%", stats.ByPrayer[name])
+			tw, _ = iup.DrawGetTextSize(ih, pct)
+			iup.DrawText(ih, pct, x+(barWidth-tw)/2, h-th-px(4)-barHeight-th, -1, -1)
+		}
+		return iup.DEFAULT
+	}))
+
+	chartFrame := iup.Frame(chart)
+	iup.SetAttribute(chartFrame, "TITLE", "On time, last 30 days")
+
+	qadaLabels := make(map[string]iup.Ihandle, len(prayerNames))
+	qadaBox := iup.Vbox()
+	refreshQada := func() {
+		counts := QadaCounts()
+		for name, label := range qadaLabels {
+			iup.SetAttribute(label, "TITLE", fmt.Sprintf("%-7s %3d", PrayerLabel(name), counts[name]))
+		}
+	}
+	for _, name := range prayerNames {
+		name := name
+
+		qadaLabels[name] = iup.Label("")
+		iup.SetAttribute(qadaLabels[name], "EXPAND", "HORIZONTAL")
+
+		madeUp := iup.Button("-")
+		iup.SetAttribute(madeUp, "TIP", "Made up one "+name)
+		iup.SetCallback(madeUp, "ACTION", iup.ActionFunc(func(ih iup.Ihandle) int {
+			AddQada(name, -1)
+			refreshQada()
+			return iup.DEFAULT
+		}))
+		missed := iup.Button("+")
+		iup.SetAttribute(missed, "TIP", "Missed one more "+name)
+		iup.SetCallback(missed, "ACTION", iup.ActionFunc(func(ih iup.Ihandle) int {
+			AddQada(name, 1)
+			refreshQada()
+			return iup.DEFAULT
+		}))
+
+		row := iup.Hbox(qadaLabels[name], madeUp, missed)
+		iup.SetAttribute(row, "ALIGNMENT", "ACENTER")
+		iup.Append(qadaBox, row)
+	}
+
+	qadaFrame := iup.Frame(qadaBox)
+	iup.SetAttribute(qadaFrame, "TITLE", "Qada")
+
+	refresh := func() {
+		stats = PrayerStats(time.Now())
+
+		mostMissed := stats.MostMissed
+		if mostMissed == "" {
+			mostMissed = "none"
+		}
+		iup.SetAttribute(summary, "TITLE", fmt.Sprintf(
+			"Streak:      %d days\nThis week:   %.0f%% on time\nThis month:  %.0f%% on time\nMost missed: %s",
+			stats.Streak, stats.Week, stats.Month, mostMissed))
+		// The chart reads out as its tip.
+		tip := "On time, last 30 days:"
+		for _, name := range prayerNames {
+			tip += fmt.Sprintf(" %s %.0f%%,", PrayerLabel(name), stats.ByPrayer[name])
+		}
+		iup.SetAttribute(chart, "TIP", strings.TrimSuffix(tip, ","))
+		iup.Update(chart)
+		refreshQada()
+	}
+
+	vbox := iup.Vbox(summary, chartFrame, qadaFrame)
+	vbox.SetAttributes(map[string]string{
+		"MARGIN": pxSize(4, 4),
+		"GAP":    scaled(4),
+	})
+
+	statsDialog = iup.Dialog(vbox)
+	iup.SetAttribute(statsDialog, "TITLE", "Statistics")
+
+	iup.SetCallback(statsDialog, "SHOW_CB", iup.ShowFunc(func(ih iup.Ihandle, state int) int {
+		if state == iup.SHOW {
+			refresh()
+		}
+		return iup.DEFAULT
+	}))
+
+	iup.Show(statsDialog)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/template"
+	"time"
+)
+
+// StatusPrayer is a prayer in the status template.
+type StatusPrayer struct {
+	Name  string
+	Label string
+	Time  time.Time
+	At    string // the time, as the timings are shown
+	In    string // the time left, as countdowns are shown
+}
+
+// Status is the data of the status template.
+type Status struct {
+	Now      time.Time
+	Location string
+	Next     StatusPrayer
+	Current  StatusPrayer
+	Prayers  []StatusPrayer
+	Hijri    HijriDate
+	Makruh   string // the makruh warning, if it's a makruh time
+}
+
+func statusPrayer(p Prayer, now time.Time) StatusPrayer {
+	sp := StatusPrayer{
+		Name:  p.Name,
+		Label: p.Label(),
+		Time:  p.Time,
+		At:    p.Time.Format(timeLayout("03:04")),
+	}
+	if p.Time.After(now) {
+		sp.In = FormatRemaining(p.Time.Sub(now))
+	}
+	return sp
+}
+
+// StatusAt returns the schedule at now.
+func StatusAt(now time.Time) Status {
+	prayers := PrayerTimings(location, now)
+	next, _ := NextPrayer(location, prayers)
+	s := Status{
+		Now:      now,
+		Location: location.Name,
+		Next:     statusPrayer(next, now),
+		Current:  statusPrayer(CurrentPrayer(location, prayers), now),
+		Hijri:    Hijri(location, now),
+	}
+	for _, p := range prayers {
+		s.Prayers = append(s.Prayers, statusPrayer(p, now))
+	}
+	for _, p := range MakruhTimes(DayTimings(location, now)) {
+		if p.Contains(now) {
+			s.Makruh = FormatMakruh(p)
+		}
+	}
+	return s
+}
+
+// statusCommand prints the schedule with a Go template, for conky, tmux
+// and panel plugins like genmon.
+func statusCommand(args []string) int {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	dir := fs.String("dir", "", "directory with the timings")
+	text := fs.String("template", "{{.Next.Label}} {{.Next.In}}", "Go template of the output, over the fields of Status")
+	fs.Parse(args)
+
+	tmpl, err := template.New("status").Parse(*text)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "status:", err)
+		return 2
+	}
+	if err := openForCommand(*dir); err != nil {
+		fmt.Fprintln(os.Stderr, "status:", err)
+		return 1
+	}
+	if err := tmpl.Execute(os.Stdout, StatusAt(time.Now())); err != nil {
+		fmt.Fprintln(os.Stderr, "status:", err)
+		return 1
+	}
+	fmt.Println()
+	return 0
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Serve a Stream Deck key on Addr, for a key set up with a web request
+// plugin: GET /key.png is the countdown to the next prayer, in the colors
+// and FontFile of the Theme, and /next the same as text, POST /stop stops
+// the sound and /mute toggles the sounds.
+var streamDeck = struct {
+	Enabled bool
+	Addr    string
+}{
+	Addr: "127.0.0.1:47414",
+}
+
+// streamDeckKeySize is the size of a Stream Deck XL key, scaled down by
+// the smaller ones.
+const streamDeckKeySize = 144
+
+// StartStreamDeck serves the Stream Deck endpoint of s.
+func StartStreamDeck(s *Scheduler) {
+	if !streamDeck.Enabled {
+		return
+	}
+
+	// state runs on the scheduler's goroutine, giving up if it's stuck.
+	type keyState struct {
+		next  Prayer
+		muted bool
+		theme string
+	}
+	state := func() (keyState, bool) {
+		ch := make(chan keyState, 1)
+		if !s.TryDo(func() { ch <- keyState{s.Upcoming(), s.Muted, themePath} }, callTimeout) {
+			return keyState{}, false
+		}
+		select {
+		case st := <-ch:
+			return st, true
+		case <-time.After(callTimeout):
+			return keyState{}, false
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/key.png", func(w http.ResponseWriter, r *http.Request) {
+		st, ok := state()
+		if !ok {
+			http.Error(w, "scheduler not running", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write(RenderKey(st.next, st.muted, st.theme))
+	})
+	mux.HandleFunc("/next", func(w http.ResponseWriter, r *http.Request) {
+		st, ok := state()
+		if !ok {
+			http.Error(w, "scheduler not running", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, "%s\n%s\n", st.next.Label(), FormatRemaining(time.Until(st.next.Time)))
+	})
+	action := func(f func()) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				http.Error(w, "use POST", http.StatusMethodNotAllowed)
+				return
+			}
+			if !s.TryDo(f, callTimeout) {
+				http.Error(w, "scheduler not running", http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+	mux.Handle("/stop", action(func() {
+		StopSound()
+		s.Dismiss()
+	}))
+	mux.Handle("/mute", action(func() {
+		s.SetMuted(!s.Muted)
+		if s.Muted {
+			StopSound()
+		}
+	}))
+
+	go func() {
+		if err := http.ListenAndServe(streamDeck.Addr, mux); err != nil {
+			fmt.Fprintln(os.Stderr, "streamdeck:", err)
+		}
+	}()
+}
+
+// keyTheme is the theme the key was last drawn with, loaded again when the
+// Theme setting changes.
+var keyTheme struct {
+	sync.Mutex
+	loaded bool
+	path   string
+	theme  Theme
+	font   *opentype.Font
+}
+
+// loadKeyTheme returns the theme at path and its font, the plain ones if
+// it doesn't load.
+func loadKeyTheme(path string) (Theme, *opentype.Font) {
+	keyTheme.Lock()
+	defer keyTheme.Unlock()
+	if keyTheme.loaded && keyTheme.path == path {
+		return keyTheme.theme, keyTheme.font
+	}
+
+	t, err := LoadTheme(path)
+	var f *opentype.Font
+	if err == nil {
+		f, err = t.KeyFont()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "streamdeck:", err)
+		t, f = Theme{}, nil
+	}
+	if f == nil {
+		if f, err = opentype.Parse(gobold.TTF); err != nil {
+			panic(err)
+		}
+	}
+	keyTheme.loaded, keyTheme.path, keyTheme.theme, keyTheme.font = true, path, t, f
+	return t, f
+}
+
+// RenderKey draws the countdown to next as a PNG key image, in the theme
+// file at theme.
+func RenderKey(next Prayer, muted bool, theme string) []byte {
+	t, keyFont := loadKeyTheme(theme)
+
+	img := image.NewRGBA(image.Rect(0, 0, streamDeckKeySize, streamDeckKeySize))
+	bg := themeColor(t.Background, color.RGBA{0x10, 0x3c, 0x2e, 0xff})
+	if muted {
+		bg = themeColor(t.Dimmed, color.RGBA{0x44, 0x44, 0x44, 0xff})
+	}
+	draw.Draw(img, img.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	fg := image.NewUniform(themeColor(t.Foreground, color.RGBA{0xff, 0xff, 0xff, 0xff}))
+
+	line := func(text string, size float64, y int) {
+		face, err := opentype.NewFace(keyFont, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			panic(err)
+		}
+		defer face.Close()
+		d := font.Drawer{Dst: img, Src: fg, Face: face}
+		x := (fixed.I(streamDeckKeySize) - d.MeasureString(text)) / 2
+		d.Dot = fixed.Point26_6{X: x, Y: fixed.I(y)}
+		d.DrawString(text)
+	}
+	line(next.Label(), 30, 52)
+	line(FormatRemaining(time.Until(next.Time)), 30, 92)
+	if muted {
+		line("muted", 20, 126)
+	}
+
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		panic(err)
+	}
+	return b.Bytes()
+}
@@ -0,0 +1,37 @@
+package main
+
+import "time"
+
+// Alarm at the start of the last third of the night, moved by Offset.
+var tahajjudAlarm = struct {
+	Enabled bool
+	Offset  Duration
+	Sound   string
+}{
+	Enabled: false,
+	Sound:   "tasbih.wav",
+}
+
+// LastThird returns the start of the last third of the night between
+// maghrib and the following fajr.
+func LastThird(maghrib, fajr time.Time) time.Time {
+	return fajr.Add(-fajr.Sub(maghrib) / 3)
+}
+
+// tahajjudEvents returns the alarm for the night ending at day's Fajr, which
+// began at the previous day's Maghrib.
+func tahajjudEvents(loc Location, day time.Time, timings map[string]time.Time) []Event {
+	if !tahajjudAlarm.Enabled {
+		return nil
+	}
+
+	maghrib := DayTimings(loc, day.AddDate(0, 0, -1))["Maghrib"]
+	t := LastThird(maghrib, timings["Fajr"]).Add(time.Duration(tahajjudAlarm.Offset))
+
+	return []Event{{
+		Name:    "Tahajjud",
+		Time:    t,
+		Message: "The last third of the night has begun",
+		Sound:   tahajjudAlarm.Sound,
+	}}
+}
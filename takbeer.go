@@ -0,0 +1,82 @@
+package main
+
+import "time"
+
+// Takbeerat every Every, on the morning of Eid al-Fitr from Fajr to the Eid
+// prayer, and from Fajr to Maghrib on Eid al-Adha and the days of Tashreeq.
+// Sound is played at its own Volume, in powers of two from the file's, and
+// the takbeer is shown in a notification when Show is set. Hours ("15:04"
+// to "15:04") limits them to part of the day, for example to keep the night
+// quiet.
+var takbeerat = struct {
+	Enabled bool
+	Every   Duration
+	Hours   [2]string
+	Sound   string
+	Volume  float64
+	Show    bool
+}{
+	Enabled: false,
+	Every:   Duration(time.Hour),
+	Sound:   "takbeerat.wav",
+	Volume:  -1,
+	Show:    true,
+}
+
+const takbeer = "اللهُ أَكْبَرُ، اللهُ أَكْبَرُ، لَا إِلَٰهَ إِلَّا اللهُ، وَاللهُ أَكْبَرُ، اللهُ أَكْبَرُ، وَلِلَّهِ الْحَمْدُ\n" +
+	"Allahu akbar, Allahu akbar, la ilaha illallah, wallahu akbar, Allahu akbar, wa lillahil hamd"
+
+// TakbeeratPeriod returns when the takbeerat are played on day, if it's in
+// an Eid season.
+func TakbeeratPeriod(h HijriDate, day time.Time, timings map[string]time.Time) (Period, bool) {
+	start, ok := timings["Fajr"]
+	if !ok {
+		return Period{}, false
+	}
+
+	var end time.Time
+	if name, eid := h.Eid(); eid && name == "Eid al-Fitr" {
+		if p, ok := EidPrayer(h, day, timings); ok {
+			end = p.Time
+		} else {
+			end = timings["Dhuhr"]
+		}
+	} else if eid || h.Tashreeq() {
+		end = timings["Maghrib"]
+	} else {
+		return Period{}, false
+	}
+	return Period{Name: "Takbeerat", Start: start, End: end}, true
+}
+
+func takbeeratEvents(loc Location, day time.Time, timings map[string]time.Time) []Event {
+	r := takbeerat
+	if !r.Enabled || r.Every <= 0 {
+		return nil
+	}
+	p, ok := TakbeeratPeriod(Hijri(loc, day), day, timings)
+	if !ok {
+		return nil
+	}
+	if r.Hours[0] != "" && r.Hours[1] != "" {
+		from, _ := EventTime(day, timings, "", 0, r.Hours[0])
+		to, _ := EventTime(day, timings, "", 0, r.Hours[1])
+		if from.After(p.Start) {
+			p.Start = from
+		}
+		if to.Before(p.End) {
+			p.End = to
+		}
+	}
+
+	ev := Event{Name: "Takbeerat", Sound: r.Sound, Volume: r.Volume}
+	if r.Show {
+		ev.Message = takbeer
+	}
+	var events []Event
+	for t := p.Start; t.Before(p.End); t = t.Add(time.Duration(r.Every)) {
+		ev.Time = t
+		events = append(events, ev)
+	}
+	return events
+}
@@ -0,0 +1,4 @@
+package main
+
+// tasbihTargets are the counts the tasbih counter beeps at.
+var tasbihTargets = []int{33, 99}
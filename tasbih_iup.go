@@ -0,0 +1,123 @@
+//go:build !gio
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gen2brain/iup-go/iup"
+)
+
+var tasbihDialog iup.Ihandle
+
+// showTasbih shows a dhikr counter. Clicking Count or pressing space
+// increments it, and a beep marks reaching the target.
+func showTasbih() {
+	if tasbihDialog != 0 {
+		iup.Show(tasbihDialog)
+		return
+	}
+
+	count, target := 0, tasbihTargets[0]
+
+	counter := iup.Label("")
+	counter.SetAttributes(map[string]string{
+		"FONTSIZE":  scaled(40),
+		"ALIGNMENT": "ACENTER:ACENTER",
+		"EXPAND":    "HORIZONTAL",
+	})
+	update := func() {
+		iup.SetAttribute(counter, "TITLE", fmt.Sprintf("%d / %d", count, target))
+	}
+	update()
+
+	increment := func() {
+		count++
+		if count%target == 0 {
+			go func() {
+				if err := PlayBeep(150 * time.Millisecond); err != nil {
+					Bell()
+				}
+			}()
+		}
+		update()
+	}
+
+	targets := iup.List()
+	targets.SetAttributes(map[string]string{
+		"DROPDOWN":     "YES",
+		"CANFOCUS":     "NO",
+		"VALUE":        "1",
+		"VISIBLEITEMS": fmt.Sprint(len(tasbihTargets)),
+	})
+	for i, t := range tasbihTargets {
+		iup.SetAttribute(targets, fmt.Sprint(i+1), t)
+	}
+	iup.SetCallback(targets, "ACTION", iup.ListActionFunc(func(ih iup.Ihandle, text string, item, state int) int {
+		if state == 1 {
+			target, _ = strconv.Atoi(text)
+			count = 0
+			update()
+		}
+		return iup.DEFAULT
+	}))
+
+	// Buttons don't take focus so space isn't handled twice.
+	countButton := iup.Button("Count")
+	countButton.SetAttributes(map[string]string{
+		"PADDING":  pxSize(20, 10),
+		"CANFOCUS": "NO",
+		"TIP":      "Count one (Space)",
+	})
+	iup.SetCallback(countButton, "ACTION", iup.ActionFunc(func(ih iup.Ihandle) int {
+		increment()
+		return iup.DEFAULT
+	}))
+
+	resetButton := iup.Button("Reset")
+	resetButton.SetAttributes(map[string]string{
+		"PADDING":  pxSize(5, 5),
+		"CANFOCUS": "NO",
+		"TIP":      "Reset the counter (R)",
+	})
+	iup.SetCallback(resetButton, "ACTION", iup.ActionFunc(func(ih iup.Ihandle) int {
+		count = 0
+		update()
+		return iup.DEFAULT
+	}))
+
+	buttons := iup.Hbox(targets, countButton, resetButton)
+	iup.SetAttribute(buttons, "ALIGNMENT", "ACENTER")
+
+	vbox := iup.Vbox(counter, buttons)
+	vbox.SetAttributes(map[string]string{
+		"ALIGNMENT": "ACENTER",
+		"MARGIN":    pxSize(4, 4),
+		"GAP":       scaled(4),
+	})
+
+	tasbihDialog = iup.Dialog(vbox)
+	tasbihDialog.SetAttributes(map[string]string{
+		"TITLE":  "Tasbih",
+		"RESIZE": "NO",
+		"MAXBOX": "NO",
+		"MINBOX": "NO",
+	})
+
+	iup.SetCallback(tasbihDialog, "K_ANY", iup.KAnyFunc(func(ih iup.Ihandle, c int) int {
+		switch c {
+		case iup.K_SP:
+			increment()
+			return iup.IGNORE
+		case iup.K_r:
+			count = 0
+			update()
+			return iup.IGNORE
+		}
+		return iup.CONTINUE
+	}))
+
+	iup.Show(tasbihDialog)
+}
@@ -0,0 +1,38 @@
+package main
+
+import "time"
+
+// Show the time elapsed towards the next prayer on the taskbar button, on
+// Windows, paused once a reminder is due and flashing at the adhan.
+var taskbarProgress = true
+
+// TaskbarState is how the taskbar progress is shown.
+type TaskbarState int
+
+const (
+	TaskbarNone   TaskbarState = iota // no progress
+	TaskbarNormal                     // green
+	TaskbarPaused                     // yellow, a reminder is due
+)
+
+// Progress returns the fraction of the time from Current to Next passed at
+// now, and the state to show it in.
+func (s *Scheduler) Progress(now time.Time) (float64, TaskbarState) {
+	total := s.Next.Time.Sub(s.Current.Time)
+	if total <= 0 {
+		return 0, TaskbarNone
+	}
+	done := now.Sub(s.Current.Time)
+	if done < 0 {
+		done = 0
+	}
+
+	state := TaskbarNormal
+	rem := s.Next.Time.Sub(now)
+	for _, r := range RemindersFor(s.Next.Name) {
+		if rem <= time.Duration(r.Before) {
+			state = TaskbarPaused
+		}
+	}
+	return float64(done) / float64(total), state
+}
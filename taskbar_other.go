@@ -0,0 +1,16 @@
+//go:build !windows
+
+package main
+
+import "errors"
+
+// Taskbar is the taskbar button of a window, only on Windows.
+type Taskbar struct{}
+
+func NewTaskbar(hwnd uintptr) (*Taskbar, error) {
+	return nil, errors.New("no taskbar progress on this system")
+}
+
+func (t *Taskbar) SetProgress(fraction float64, state TaskbarState) {}
+
+func (t *Taskbar) Flash() {}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	ole32                = windows.NewLazySystemDLL("ole32.dll")
+	procCoCreateInstance = ole32.NewProc("CoCreateInstance")
+	user32               = windows.NewLazySystemDLL("user32.dll")
+	procFlashWindowEx    = user32.NewProc("FlashWindowEx")
+
+	clsidTaskbarList = windows.GUID{Data1: 0x56fdf344, Data2: 0xfd6d, Data3: 0x11d0, Data4: [8]byte{0x95, 0x8a, 0x00, 0x60, 0x97, 0xc9, 0xa0, 0x90}}
+	iidTaskbarList3  = windows.GUID{Data1: 0xea1afb91, Data2: 0x9e28, Data3: 0x4b86, Data4: [8]byte{0x90, 0xe9, 0x9e, 0x9f, 0x8a, 0x5e, 0xef, 0xaf}}
+)
+
+// taskbarList is an ITaskbarList3, with the methods up to SetProgressState.
+type taskbarList struct {
+	vtbl *struct {
+		QueryInterface, AddRef, Release                          uintptr
+		HrInit, AddTab, DeleteTab, ActivateTab, SetActiveAlt     uintptr
+		MarkFullscreenWindow, SetProgressValue, SetProgressState uintptr
+	}
+}
+
+// Taskbar is the taskbar button of a window.
+type Taskbar struct {
+	hwnd  uintptr
+	list  *taskbarList
+	state TaskbarState
+}
+
+// NewTaskbar returns the taskbar button of the window hwnd. It has to be
+// used from the window's thread.
+func NewTaskbar(hwnd uintptr) (*Taskbar, error) {
+	// Already initialized by the GUI toolkit, most likely.
+	windows.CoInitializeEx(0, windows.COINIT_APARTMENTTHREADED)
+
+	var list *taskbarList
+	hr, _, _ := procCoCreateInstance.Call(
+		uintptr(unsafe.Pointer(&clsidTaskbarList)), 0, windows.CLSCTX_INPROC_SERVER,
+		uintptr(unsafe.Pointer(&iidTaskbarList3)), uintptr(unsafe.Pointer(&list)))
+	if hr != 0 {
+		return nil, syscall.Errno(hr)
+	}
+	if hr, _, _ := syscall.SyscallN(list.vtbl.HrInit, uintptr(unsafe.Pointer(list))); hr != 0 {
+		syscall.SyscallN(list.vtbl.Release, uintptr(unsafe.Pointer(list)))
+		return nil, syscall.Errno(hr)
+	}
+	return &Taskbar{hwnd: hwnd, list: list, state: -1}, nil
+}
+
+// SetProgress shows fraction, from 0 to 1, in state.
+func (t *Taskbar) SetProgress(fraction float64, state TaskbarState) {
+	this := uintptr(unsafe.Pointer(t.list))
+	if state != t.state {
+		// TBPF_NOPROGRESS, TBPF_NORMAL and TBPF_PAUSED.
+		flags := map[TaskbarState]uintptr{TaskbarNone: 0, TaskbarNormal: 2, TaskbarPaused: 8}[state]
+		syscall.SyscallN(t.list.vtbl.SetProgressState, this, t.hwnd, flags)
+		t.state = state
+	}
+	if state != TaskbarNone {
+		const total = 10000
+		syscall.SyscallN(t.list.vtbl.SetProgressValue, this, t.hwnd, uintptr(fraction*total), total)
+	}
+}
+
+// Flash flashes the button until the window comes to the foreground.
+func (t *Taskbar) Flash() {
+	info := struct {
+		size    uint32
+		hwnd    uintptr
+		flags   uint32
+		count   uint32
+		timeout uint32
+	}{hwnd: t.hwnd, flags: 2 | 12} // FLASHW_TRAY | FLASHW_TIMERNOFG
+	info.size = uint32(unsafe.Sizeof(info))
+	procFlashWindowEx.Call(uintptr(unsafe.Pointer(&info)))
+}
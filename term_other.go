@@ -0,0 +1,9 @@
+//go:build !linux && !windows && !darwin
+
+package main
+
+import "errors"
+
+func rawTerminal() (restore func(), err error) {
+	return nil, errors.New("not supported on this system")
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/image/font/opentype"
+)
+
+// themePath is the theme file branding the signage display and the Stream
+// Deck key, or "" for the plain look. The display loads it when it starts.
+var themePath = ""
+
+// Theme is a theme file, JSON of these fields, any of them left out for the
+// plain look. The BackgroundImage and FontFile are relative to the theme
+// file, so a theme pack can be copied as a directory.
+type Theme struct {
+	Foreground      string // "R G B" or "#RRGGBB", of the text
+	Background      string
+	Dimmed          string // of the text while signage is dimmed, and the muted key
+	Font            string // face of the signage text
+	FontFile        string // TrueType or OpenType font of the key
+	BackgroundImage string // PNG or JPEG behind the signage display
+	Layout          string // of the timetable: "rows" of prayers or "columns"
+
+	dir string
+}
+
+// LoadTheme reads and checks the theme file at path, the plain theme if
+// path is "".
+func LoadTheme(path string) (Theme, error) {
+	var t Theme
+	if path == "" {
+		return t, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return t, err
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&t); err != nil {
+		return Theme{}, fmt.Errorf("%s: %w", path, err)
+	}
+	t.dir = filepath.Dir(path)
+	if err := t.validate(); err != nil {
+		return Theme{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return t, nil
+}
+
+func (t Theme) validate() error {
+	for _, c := range []struct{ name, v string }{
+		{"Foreground", t.Foreground}, {"Background", t.Background}, {"Dimmed", t.Dimmed},
+	} {
+		if _, err := ParseColor(c.v); c.v != "" && err != nil {
+			return fmt.Errorf("%s: %w", c.name, err)
+		}
+	}
+	if t.Layout != "" && t.Layout != "rows" && t.Layout != "columns" {
+		return fmt.Errorf("Layout %q is not rows or columns", t.Layout)
+	}
+	if _, err := t.Image(); err != nil {
+		return err
+	}
+	_, err := t.KeyFont()
+	return err
+}
+
+// Image returns the BackgroundImage, nil if there's none.
+func (t Theme) Image() (image.Image, error) {
+	if t.BackgroundImage == "" {
+		return nil, nil
+	}
+	f, err := os.Open(filepath.Join(t.dir, t.BackgroundImage))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("BackgroundImage %s: %w", t.BackgroundImage, err)
+	}
+	return img, nil
+}
+
+// KeyFont returns the FontFile, nil if there's none.
+func (t Theme) KeyFont() (*opentype.Font, error) {
+	if t.FontFile == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filepath.Join(t.dir, t.FontFile))
+	if err != nil {
+		return nil, err
+	}
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("FontFile %s: %w", t.FontFile, err)
+	}
+	return f, nil
+}
+
+// ParseColor reads a color as IUP writes them, "R G B", or as "#RRGGBB".
+func ParseColor(s string) (color.RGBA, error) {
+	c := color.RGBA{A: 0xff}
+	var err error
+	if strings.HasPrefix(s, "#") {
+		_, err = fmt.Sscanf(s, "#%02x%02x%02x", &c.R, &c.G, &c.B)
+	} else {
+		_, err = fmt.Sscanf(s, "%d %d %d", &c.R, &c.G, &c.B)
+	}
+	if err != nil {
+		return c, fmt.Errorf("%q is not a color like \"255 255 255\" or \"#ffffff\"", s)
+	}
+	return c, nil
+}
+
+// themeColor returns the color s of a theme, or def if the theme leaves it
+// out.
+func themeColor(s string, def color.RGBA) color.RGBA {
+	if c, err := ParseColor(s); err == nil {
+		return c
+	}
+	return def
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// The IANA time zone, like "Asia/Riyadh", the timings are shown in and the
+// days start in, for a machine whose clock is in another zone than the
+// location, such as a server in Frankfurt serving the timings of Makkah.
+// "" keeps the machine's zone, the timings being shown in the zone the API
+// gives for the location. A change while running applies at the next
+// restart.
+var timeZone = ""
+
+// appliedZone is the timeZone time.Local was set to, once zoneApplied,
+// which is the one in use until a restart.
+var (
+	appliedZone string
+	zoneApplied bool
+)
+
+// applyTimeZone makes timeZone the local zone of the app. time.Local is
+// read by every goroutine without a lock, so it's only set the first time,
+// as the config is loaded before they start.
+func applyTimeZone() error {
+	if zoneApplied {
+		if timeZone != appliedZone {
+			fmt.Fprintln(os.Stderr, "config: TimeZone", timeZone, "applies when Prayer restarts")
+		}
+		return nil
+	}
+	if timeZone != "" {
+		zone, err := time.LoadLocation(timeZone)
+		if err != nil {
+			return err
+		}
+		time.Local = zone
+	}
+	appliedZone, zoneApplied = timeZone, true
+	return nil
+}
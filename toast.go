@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Schedule the adhan notifications with Windows once the timings are known,
+// so they show with a sound on the lock screen too. They're on top of the
+// app's own alerts, and none are scheduled while muted or after quitting.
+var lockScreenToasts = false
+
+// Toast is a notification scheduled with the system.
+type Toast struct {
+	Time           time.Time
+	Title, Message string
+}
+
+var toastMu sync.Mutex // one ScheduleToasts at a time
+
+// scheduleToasts schedules a toast for each of the prayers still to come,
+// or none when muted.
+func (s *Scheduler) scheduleToasts(BusEvent) {
+	if !lockScreenToasts {
+		return
+	}
+	var toasts []Toast
+	now := time.Now()
+	for _, p := range s.Prayers {
+		if p.Time.After(now) && !s.Muted {
+			toasts = append(toasts, Toast{Time: p.Time, Title: p.Label(), Message: "Time for " + p.Label()})
+		}
+	}
+
+	go func() {
+		toastMu.Lock()
+		defer toastMu.Unlock()
+		if err := ScheduleToasts(toasts); err != nil {
+			fmt.Fprintln(os.Stderr, "toasts:", err)
+		}
+	}()
+}
+
+// clearToasts removes the toasts scheduled, waiting for it.
+func clearToasts() {
+	if !lockScreenToasts {
+		return
+	}
+	toastMu.Lock()
+	defer toastMu.Unlock()
+	if err := ScheduleToasts(nil); err != nil {
+		fmt.Fprintln(os.Stderr, "toasts:", err)
+	}
+}
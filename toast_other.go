@@ -0,0 +1,8 @@
+//go:build !windows
+
+package main
+
+// ScheduleToasts does nothing, the running app shows the alerts.
+func ScheduleToasts(toasts []Toast) error {
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// The app the toasts are shown as, PowerShell's as Prayer has no
+// registered one.
+const toastAppID = `{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe`
+
+const toastScript = `
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+[Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime] > $null
+$notifier = [Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('%s')
+foreach ($t in $notifier.GetScheduledToastNotifications()) {
+	if ($t.Group -eq 'prayer') { $notifier.RemoveFromSchedule($t) }
+}
+function Add-Toast($unix, $content) {
+	$xml = New-Object Windows.Data.Xml.Dom.XmlDocument
+	$xml.LoadXml($content)
+	$toast = New-Object Windows.UI.Notifications.ScheduledToastNotification $xml, ([DateTimeOffset]::FromUnixTimeSeconds($unix))
+	$toast.Group = 'prayer'
+	$notifier.AddToSchedule($toast)
+}
+`
+
+// ScheduleToasts replaces the toasts scheduled before with toasts.
+func ScheduleToasts(toasts []Toast) error {
+	var script strings.Builder
+	fmt.Fprintf(&script, toastScript, toastAppID)
+	for _, t := range toasts {
+		content := fmt.Sprintf(`<toast><visual><binding template="ToastGeneric"><text>%s</text><text>%s</text></binding></visual>`+
+			`<audio src="ms-winsoundevent:Notification.Reminder"/></toast>`, xmlEscape(t.Title), xmlEscape(t.Message))
+		fmt.Fprintf(&script, "Add-Toast %d '%s'\n", t.Time.Unix(), strings.ReplaceAll(content, "'", "''"))
+	}
+
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script.String())
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, out)
+	}
+	return nil
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
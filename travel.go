@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+)
+
+// Travel mode looks the location up from the public IP address every
+// travelCheckInterval, sending the address to ipapi.co, so it's off until
+// turned on.
+var (
+	travelMode          = false
+	osLocation          = false // use OS location services instead of the IP lookup
+	travelCheckInterval = 30 * time.Minute
+	travelDistance      = 50.0 // km
+)
+
+const geoipUrl = "https://ipapi.co/json/"
+
+// geoipClient looks the location up, skipping the check when it's slow.
+var geoipClient = &http.Client{Timeout: 10 * time.Second}
+
+// declinedLocation is the last location the user refused to switch to, so
+// they are not asked again until they move somewhere else.
+var declinedLocation *Location
+
+// Distance returns the great-circle distance between a and b in km.
+func Distance(a, b Location) float64 {
+	const earthRadius = 6371.0
+
+	phi1 := a.Latitude * math.Pi / 180
+	phi2 := b.Latitude * math.Pi / 180
+	dPhi := phi2 - phi1
+	dLambda := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dPhi/2)*math.Sin(dPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// DetectLocation looks up the current location from the public IP address.
+func DetectLocation() (Location, error) {
+	resp, err := geoipClient.Get(geoipUrl)
+	if err != nil {
+		return Location{}, err
+	}
+	defer resp.Body.Close()
+
+	var r struct {
+		Error     bool
+		Reason    string
+		City      string
+		Latitude  float64
+		Longitude float64
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return Location{}, err
+	}
+	if r.Error {
+		return Location{}, errors.New("geoip: " + r.Reason)
+	}
+	return Location{Name: r.City, Latitude: r.Latitude, Longitude: r.Longitude}, nil
+}
+
+// detectLocation prefers OS location services when enabled, falling back to
+// the IP lookup.
+func detectLocation() (Location, error) {
+	if osLocation {
+		if loc, err := OSLocation(); err == nil {
+			if loc.Name == "" {
+				loc.Name = fmt.Sprintf("%.2f, %.2f", loc.Latitude, loc.Longitude)
+			}
+			return loc, nil
+		}
+	}
+	return DetectLocation()
+}
+
+// watchLocation sends the detected location to detected every
+// travelCheckInterval. Lookups that fail are skipped.
+func watchLocation(detected chan<- Location) {
+	for {
+		if loc, err := detectLocation(); err == nil {
+			select {
+			case detected <- loc:
+			default:
+			}
+		}
+		time.Sleep(travelCheckInterval)
+	}
+}
+
+// nextProfile returns the location profile after the active one.
+func nextProfile() Location {
+	for i, p := range profiles {
+		if p.Is(location) {
+			return profiles[(i+1)%len(profiles)]
+		}
+	}
+	return profiles[0]
+}
+
+// promptLocationChange asks to switch to loc if it is far from the active
+// location, with confirm showing the question. The nearest matching profile
+// is used when there is one.
+func promptLocationChange(loc Location, confirm func(msg string) bool) (Location, bool) {
+	if Distance(loc, location) < travelDistance {
+		return location, false
+	}
+	if declinedLocation != nil && Distance(loc, *declinedLocation) < travelDistance {
+		return location, false
+	}
+
+	for _, p := range profiles {
+		if Distance(loc, p) < travelDistance {
+			loc = p
+			break
+		}
+	}
+
+	msg := fmt.Sprintf("You seem to be in %s, about %.0f km from %s.\nSwitch prayer times to %s?",
+		loc.Name, Distance(loc, location), location.Name, loc.Name)
+	if !confirm(msg) {
+		declinedLocation = &loc
+		return location, false
+	}
+
+	declinedLocation = nil
+	for _, p := range profiles {
+		if p.Is(loc) {
+			return loc, true
+		}
+	}
+	profiles = append(profiles, loc)
+	return loc, true
+}
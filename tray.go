@@ -0,0 +1,10 @@
+package main
+
+// On Wayland, show the tray icon as a StatusNotifierItem instead of with IUP.
+var sniTray = true
+
+// TrayItem is an entry of the tray menu. An empty Label is a separator.
+type TrayItem struct {
+	Label  string
+	Action func()
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// tuiCommand runs the scheduler in the terminal, with today's timings and a
+// countdown to the next prayer, for machines without a desktop.
+func tuiCommand(args []string) int {
+	fs := flag.NewFlagSet("tui", flag.ExitOnError)
+	dir := fs.String("dir", "", "directory with the sounds and timings")
+	fs.Parse(args)
+
+	if *dir != "" {
+		if err := os.Chdir(*dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	if _, ok := SingleInstance(); !ok {
+		fmt.Fprintln(os.Stderr, "Prayer is already running")
+		return 1
+	}
+
+	if err := LoadConfig(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	OpenLog()
+	RestoreProfile()
+
+	restore, err := rawTerminal()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "tui:", err)
+		return 1
+	}
+	// Alternate screen, hidden cursor.
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer func() {
+		fmt.Print("\x1b[?25h\x1b[?1049l")
+		restore()
+	}()
+
+	var status string
+	sched := NewScheduler()
+	StartIntegrations(sched)
+	sched.Notify = func(title, message string) {
+		status = time.Now().Format("15:04") + "  " + title + ": " + message
+	}
+
+	keys := make(chan byte)
+	go func() {
+		r := bufio.NewReader(os.Stdin)
+		for {
+			b, err := r.ReadByte()
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- b
+		}
+	}()
+
+	configChanged := make(chan bool, 1)
+	if configReload {
+		go watchConfig(configChanged)
+	}
+
+	drawTUI(sched, status)
+	signals := ShutdownSignals()
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+	defer sched.Shutdown()
+	for {
+		select {
+		case now := <-tick.C:
+			sched.Tick(now)
+		case <-configChanged:
+			if err := sched.ReloadConfig(); err != nil {
+				sched.Notify("Config not applied", err.Error())
+			}
+		case <-signals:
+			return 0
+		case k, ok := <-keys:
+			if !ok {
+				return 0
+			}
+			switch k {
+			case 'q', 3: // Ctrl+C
+				return 0
+			case 'm':
+				sched.SetMuted(!sched.Muted)
+			case 'x':
+				StopSound()
+				sched.Dismiss()
+			case 's':
+				if sched.Snooze(snoozeDuration) {
+					status = fmt.Sprintf("Snoozed for %v", snoozeDuration)
+				}
+			case 'p':
+				MarkPrayed(location, sched.Current)
+			}
+		}
+		drawTUI(sched, status)
+	}
+}
+
+func drawTUI(sched *Scheduler, status string) {
+	now := time.Now()
+	var b strings.Builder
+
+	// Home and clear.
+	b.WriteString("\x1b[H\x1b[2J")
+	fmt.Fprintf(&b, "Prayer times in %s, %s\r\n\r\n", location.Name, now.Format("Mon 2 Jan 2006"))
+
+	if !sched.Imsak.Time.IsZero() {
+		fmt.Fprintf(&b, "   %-8s %s\r\n", sched.Imsak.Label(), sched.Imsak.Time.Format(timeLayout("15:04")))
+	}
+	for _, p := range sched.Prayers {
+		marker, prayed := " ", ""
+		if p.Name == sched.Next.Name {
+			marker = ">"
+		}
+		if IsPrayed(p) {
+			prayed = "✓"
+		}
+		fmt.Fprintf(&b, " %s %-8s %s  %s\r\n", marker, p.Label(), p.Time.Format(timeLayout("15:04")), prayed)
+	}
+
+	for _, p := range sched.Extra {
+		fmt.Fprintf(&b, "   %-8s %s\r\n", p.Label(), p.Time.Format(timeLayout("15:04")))
+	}
+
+	next := sched.Upcoming()
+	fmt.Fprintf(&b, "\r\nNext prayer is %s %s\r\n", next.Label(), FormatUntil("after", next.Time))
+	if rem := sched.CurrentEnd.Sub(now); rem > 0 {
+		fmt.Fprintf(&b, "%s\r\n", FormatWindow(sched.Current, rem))
+	}
+	if s := FormatImsak(sched.Imsak); s != "" {
+		fmt.Fprintf(&b, "%s\r\n", s)
+	}
+	if sched.DSTNotice != "" {
+		fmt.Fprintf(&b, "%s\r\n", sched.DSTNotice)
+	}
+	if p, ok := sched.MakruhAt(now); ok {
+		fmt.Fprintf(&b, "%s\r\n", FormatMakruh(p))
+	}
+
+	fmt.Fprintf(&b, "\r\n%s\r\n\r\n", status)
+	b.WriteString("[m] mute  [x] stop sound  [s] snooze  [p] prayed  [q] quit")
+	if sched.Muted {
+		b.WriteString("   (muted)")
+	}
+	fmt.Print(b.String())
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// The prayers shown coming up in the window, the next one and those after
+// it, running into the next day.
+var upcomingCount = 3
+
+// UpcomingPrayers returns the first n prayers after now of days, the
+// timings of days in order. A day may be given twice, as the scheduler's
+// Prayers and Tomorrow are after Isha.
+func UpcomingPrayers(now time.Time, n int, days ...Prayers) Prayers {
+	var upcoming Prayers
+	for _, prayers := range days {
+		for _, p := range prayers {
+			if p.Time.After(now) && len(upcoming) < n {
+				upcoming = append(upcoming, p)
+				now = p.Time
+			}
+		}
+	}
+	return upcoming
+}
+
+// FormatUpcoming formats prayers a line each, with the countdown to it.
+func FormatUpcoming(prayers Prayers) string {
+	var lines []string
+	for _, p := range prayers {
+		line := p.String()
+		if countdownFormat != "time" {
+			line += "  " + FormatUntil("in", p.Time)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FormatTomorrowFajr is what's shown of next, the next prayer, when it's
+// tomorrow's Fajr, as after Isha: the time to wake up at, before going to
+// bed. It's "" otherwise.
+func FormatTomorrowFajr(next Prayer, now time.Time) string {
+	if next.Name != "Fajr" || sameDay(next.Time, now) {
+		return ""
+	}
+	return fmt.Sprintf("Tomorrow's %s is at %s", next.Label(), next.Time.Format(timeLayout("03:04")))
+}
+
+// TimingsTitle is the title of the timetable of prayers, saying when it's
+// already tomorrow's.
+func TimingsTitle(prayers Prayers, now time.Time) string {
+	if len(prayers) > 0 && prayers[0].Time.After(now) && !sameDay(prayers[0].Time, now) {
+		return "Tomorrow's prayer times"
+	}
+	return "Prayers times"
+}
+
+// sameDay reports whether a and b are on the same day here.
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.In(time.Local).Date()
+	by, bm, bd := b.In(time.Local).Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// nextCommand prints the next prayers with their countdowns.
+func nextCommand(args []string) int {
+	fs := flag.NewFlagSet("next", flag.ExitOnError)
+	dir := fs.String("dir", "", "directory with the timings")
+	n := fs.Int("n", upcomingCount, "number of prayers")
+	fs.Parse(args)
+	if err := openForCommand(*dir); err != nil {
+		fmt.Fprintln(os.Stderr, "next:", err)
+		return 1
+	}
+	now := time.Now()
+	var days []Prayers
+	for d := 0; d <= *n/len(prayerNames)+1; d++ {
+		days = append(days, PrayerTimings(location, now.AddDate(0, 0, d)))
+	}
+	fmt.Println(FormatUpcoming(UpcomingPrayers(now, *n, days...)))
+	return 0
+}
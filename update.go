@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Set at build time with -ldflags "-X main.version=v1.2.3".
+var version = "dev"
+
+var checkUpdates = false // look for new releases on startup
+
+const releasesUrl = "https://api.github.com/repos/eid-setf/prayer-go/releases/latest"
+
+// updateClient gets the releases, with a timeout long enough to download
+// a build on a slow connection.
+var updateClient = &http.Client{Timeout: 5 * time.Minute}
+
+// A release has a build of each platform named by assetName, and checksums
+// of them in SHA256SUMS, as written by sha256sum.
+const checksumsAsset = "SHA256SUMS"
+
+// assetName is the name of the build of this platform in a release.
+func assetName() string {
+	name := "prayer-" + runtime.GOOS + "-" + runtime.GOARCH
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+type Release struct {
+	TagName string `json:"tag_name"`
+	HtmlUrl string `json:"html_url"`
+	Assets  []struct {
+		Name               string
+		BrowserDownloadUrl string `json:"browser_download_url"`
+	}
+}
+
+// CheckUpdate returns the latest release if it's newer than this build.
+func CheckUpdate() (Release, bool, error) {
+	resp, err := updateClient.Get(releasesUrl)
+	if err != nil {
+		return Release{}, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Release{}, false, fmt.Errorf("github: %s", resp.Status)
+	}
+
+	var r Release
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return Release{}, false, err
+	}
+	return r, newerVersion(r.TagName, version), nil
+}
+
+// newerVersion reports whether tag is a later vMAJOR.MINOR.PATCH than
+// current. Development builds never update.
+func newerVersion(tag, current string) bool {
+	parse := func(v string) []int {
+		parts := strings.Split(strings.TrimPrefix(v, "v"), ".")
+		n := make([]int, len(parts))
+		for i, p := range parts {
+			n[i], _ = strconv.Atoi(p)
+		}
+		return n
+	}
+	if current == "dev" {
+		return false
+	}
+
+	a, b := parse(tag), parse(current)
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] > b[i]
+		}
+	}
+	return len(a) > len(b)
+}
+
+// releaseChecksum returns the SHA-256 of the asset name in r's checksums.
+func releaseChecksum(r Release, name string) ([]byte, error) {
+	url := r.assetUrl(checksumsAsset)
+	if url == "" {
+		return nil, fmt.Errorf("no %s in release %s", checksumsAsset, r.TagName)
+	}
+	resp, err := updateClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(checksumsAsset + ": " + resp.Status)
+	}
+
+	lines := bufio.NewScanner(io.LimitReader(resp.Body, 1<<20))
+	for lines.Scan() {
+		fields := strings.Fields(lines.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return hex.DecodeString(fields[0])
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%s has no checksum of %s", checksumsAsset, name)
+}
+
+// assetUrl returns the download URL of r's asset name, "" without one.
+func (r Release) assetUrl(name string) string {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a.BrowserDownloadUrl
+		}
+	}
+	return ""
+}
+
+// InstallUpdate downloads the release binary for this platform, checks it
+// against the release's checksums and swaps it with the running
+// executable, which keeps running until restarted.
+func InstallUpdate(r Release) error {
+	name := assetName()
+	url := r.assetUrl(name)
+	if url == "" {
+		return fmt.Errorf("no %s in release %s", name, r.TagName)
+	}
+	sum, err := releaseChecksum(r, name)
+	if err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return err
+	}
+
+	resp, err := updateClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("download: " + resp.Status)
+	}
+
+	newExe := exe + ".new"
+	f, err := os.OpenFile(newExe, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, h), resp.Body); err != nil {
+		f.Close()
+		os.Remove(newExe)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(newExe)
+		return err
+	}
+	if !bytes.Equal(h.Sum(nil), sum) {
+		os.Remove(newExe)
+		return fmt.Errorf("%s doesn't match its checksum in %s", name, checksumsAsset)
+	}
+
+	// A running executable can be renamed but not overwritten on Windows.
+	oldExe := exe + ".old"
+	os.Remove(oldExe)
+	if err := os.Rename(exe, oldExe); err != nil {
+		os.Remove(newExe)
+		return err
+	}
+	if err := os.Rename(newExe, exe); err != nil {
+		os.Rename(oldExe, exe)
+		return err
+	}
+	return nil
+}
+
+func updateCommand(args []string) int {
+	r, newer, err := CheckUpdate()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "update:", err)
+		return 1
+	}
+	if !newer {
+		fmt.Printf("Prayer %s is up to date\n", version)
+		return 0
+	}
+
+	fmt.Printf("Updating Prayer %s to %s...\n", version, r.TagName)
+	if err := InstallUpdate(r); err != nil {
+		fmt.Fprintln(os.Stderr, "update:", err)
+		return 1
+	}
+	fmt.Println("Updated, restart Prayer to use the new version")
+	return 0
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// ValidateLocation checks loc's coordinates are on Earth.
+func ValidateLocation(loc Location) error {
+	if loc.Latitude < -90 || loc.Latitude > 90 {
+		return fmt.Errorf("%s: latitude %v is not between -90 and 90", loc.Name, loc.Latitude)
+	}
+	if loc.Longitude < -180 || loc.Longitude > 180 {
+		return fmt.Errorf("%s: longitude %v is not between -180 and 180", loc.Name, loc.Longitude)
+	}
+	return nil
+}
+
+var weekdays = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+// ValidateSettings checks the settings make sense, returning every problem
+// found rather than letting them turn into bad requests or alerts.
+func ValidateSettings() error {
+	var errs []error
+	check := func(ok bool, format string, a ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, a...))
+		}
+	}
+	oneOf := func(setting, v string, values ...string) {
+		for _, s := range values {
+			if v == s {
+				return
+			}
+		}
+		errs = append(errs, fmt.Errorf("%s %q is not one of %q", setting, v, values))
+	}
+	// Offsets from a timing stay within the day.
+	offset := func(setting string, d Duration) {
+		check(time.Duration(d) > -24*time.Hour && time.Duration(d) < 24*time.Hour,
+			"%s %v is not within a day", setting, time.Duration(d))
+	}
+	clock := func(setting, at string) {
+		if _, err := time.Parse("15:04", at); at != "" && err != nil {
+			errs = append(errs, fmt.Errorf("%s %q is not a time like 15:04", setting, at))
+		}
+	}
+	positive := func(setting string, d time.Duration) {
+		check(d > 0, "%s %v is not positive", setting, d)
+	}
+
+	for _, loc := range append(append([]Location{location}, profiles...), cities...) {
+		if err := ValidateLocation(loc); err != nil {
+			errs = append(errs, err)
+		}
+		if loc.Method != nil {
+			_, ok := calcMethods[*loc.Method]
+			check(ok, "%s: method %d is not a known calculation method", loc.Name, *loc.Method)
+		}
+	}
+	_, ok := calcMethods[method]
+	check(ok, "Method %d is not a known calculation method", method)
+	check(school == 0 || school == 1, "School %d is not 0 (Shafi) or 1 (Hanafi)", school)
+	oneOf("MidnightMode", midnightMode, "", "standard", "jafari")
+	oneOf("Shafaq", shafaq, "general", "ahmer", "abyad")
+	oneOf("Rounding", rounding, "nearest", "up", "safe", "none")
+	oneOf("CountdownFormat", countdownFormat, "clock", "minutes", "words", "time")
+	if _, ok := prayerNameStyles[prayerNameStyle]; !ok && prayerNameStyle != "" {
+		errs = append(errs, fmt.Errorf("PrayerNameStyle %q is not known", prayerNameStyle))
+	}
+
+	for _, r := range defaultReminders {
+		check(r.Before >= 0, "Reminders: Before %v is negative", time.Duration(r.Before))
+		offset("Reminders: Before", r.Before)
+	}
+	for name, rs := range prayerReminders {
+		for _, r := range rs {
+			check(r.Before >= 0, "PrayerReminders: %s Before %v is negative", name, time.Duration(r.Before))
+			offset("PrayerReminders: "+name+" Before", r.Before)
+		}
+	}
+	for _, r := range adhkarReminders {
+		offset("AdhkarReminders: "+r.Name+" Offset", r.Offset)
+	}
+	for _, r := range customReminders {
+		offset("CustomReminders: "+r.Name+" Offset", r.Offset)
+		check(r.After != "" || r.At != "", "CustomReminders: %s has no After or At", r.Name)
+		clock("CustomReminders: "+r.Name+" At", r.At)
+		if r.City != "" {
+			_, ok := findCity(r.City)
+			check(ok, "CustomReminders: %s City %q is not one of the Cities", r.Name, r.City)
+		}
+		for _, d := range r.Days {
+			oneOf("CustomReminders: "+r.Name+" Days", d, weekdays...)
+		}
+	}
+	if weeklyEmail.Enabled {
+		oneOf("WeeklyEmail Day", weeklyEmail.Day, weekdays...)
+		offset("WeeklyEmail Offset", weeklyEmail.Offset)
+		_, _, err := net.SplitHostPort(weeklyEmail.Server)
+		check(err == nil, "WeeklyEmail Server %q is not host:port", weeklyEmail.Server)
+		check(weeklyEmail.From != "" && len(weeklyEmail.To) > 0, "WeeklyEmail needs From and To")
+	}
+	if server.Enabled {
+		_, _, err := net.SplitHostPort(server.Addr)
+		check(err == nil, "Server Addr %q is not host:port", server.Addr)
+		if server.GRPCAddr != "" {
+			_, _, err := net.SplitHostPort(server.GRPCAddr)
+			check(err == nil, "Server GRPCAddr %q is not host:port", server.GRPCAddr)
+		}
+		check((server.CertFile == "") == (server.KeyFile == ""), "Server needs both a CertFile and a KeyFile")
+		check(server.RateLimit >= 0, "Server RateLimit %v is negative", server.RateLimit)
+		check(server.CacheFor >= 0, "Server CacheFor %v is negative", time.Duration(server.CacheFor))
+	}
+	for name, d := range iqama {
+		check(d >= 0, "Iqama: %s %v is negative", name, time.Duration(d))
+		offset("Iqama: "+name, d)
+	}
+	for _, a := range signage.Announcements {
+		if err := a.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("Signage: %w", err))
+		}
+	}
+	check(signage.DimAfter >= 0 && signage.DimBefore >= 0, "Signage DimAfter and DimBefore can't be negative")
+	check(signage.PrayerLength >= 0, "Signage PrayerLength %v is negative", time.Duration(signage.PrayerLength))
+	if _, err := LoadTheme(themePath); err != nil {
+		errs = append(errs, fmt.Errorf("Theme: %w", err))
+	}
+	offset("KahfReminder Offset", kahfReminder.Offset)
+	clock("KahfReminder At", kahfReminder.At)
+	clock("DuhaReminder At", duhaReminder.At)
+	offset("TahajjudAlarm Offset", tahajjudAlarm.Offset)
+	offset("DuhaReminder Offset", duhaReminder.Offset)
+	offset("FastingReminders Offset", fastingReminders.Offset)
+	offset("EidPrayer Offset", eidPrayer.Offset)
+	offset("WindowAlert Before", windowAlert.Before)
+
+	for _, n := range notifiers {
+		oneOf("Notifiers: Type", n.Type, notifierTypes...)
+		if n.QuietHours != [2]string{} {
+			check(n.QuietHours[0] != "" && n.QuietHours[1] != "", "Notifiers: %s QuietHours needs a start and an end", n.Type)
+			clock("Notifiers: "+n.Type+" QuietHours", n.QuietHours[0])
+			clock("Notifiers: "+n.Type+" QuietHours", n.QuietHours[1])
+		}
+		switch n.Type {
+		case "webhook", "slack", "discord":
+			check(n.URL != "", "Notifiers: %s has no URL", n.Type)
+		case "mqtt":
+			check(n.URL != "" && n.Topic != "", "Notifiers: mqtt needs a URL and a Topic")
+		case "matrix":
+			check(n.URL != "" && n.Token != "" && n.Room != "", "Notifiers: matrix needs a URL, a Token and a Room")
+		case "ntfy":
+			check(n.Topic != "", "Notifiers: ntfy has no Topic")
+		case "gotify":
+			check(n.URL != "" && n.Token != "", "Notifiers: gotify needs a URL and a Token")
+		case "gateway":
+			check(n.URL != "", "Notifiers: gateway has no URL")
+			_, err := gatewayTemplate(n.Body)
+			check(err == nil, "Notifiers: gateway Body: %v", err)
+		case "telegram":
+			check(n.Token != "" && n.ChatID != "", "Notifiers: telegram needs a Token and a ChatID")
+		}
+	}
+
+	positive("TravelCheckInterval", travelCheckInterval)
+	positive("Snooze", snoozeDuration)
+	positive("DuaTimeout", duaTimeout)
+	if takbeerat.Enabled {
+		positive("Takbeerat Every", time.Duration(takbeerat.Every))
+	}
+	if escalation.Enabled {
+		positive("Escalation After", time.Duration(escalation.After))
+	}
+	check(uiScale >= 0, "UIScale %v is negative", uiScale)
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		errs = append(errs, fmt.Errorf("TimeZone: %w", err))
+	}
+	check(upcomingCount >= 0, "UpcomingPrayers %d is negative", upcomingCount)
+	check(prefetchMonths >= 0, "PrefetchMonths %d is negative", prefetchMonths)
+	check(cache.KeepMonths >= 0 && cache.KeepLocationDays >= 0, "Cache KeepMonths and KeepLocationDays can't be negative")
+
+	return errors.Join(errs...)
+}
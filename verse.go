@@ -0,0 +1,119 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	showVerse   = true
+	onlineVerse = false // fetch a verse from alquran.cloud instead of the embedded set
+)
+
+const (
+	verseApiUrl = "https://api.alquran.cloud/v1/ayah/%d/editions/quran-uthmani,en.sahih"
+	ayahCount   = 6236
+)
+
+//go:embed verses.json
+var versesJson []byte
+
+// verses are those of versesJson.
+var verses = func() []Verse {
+	var v []Verse
+	if err := json.Unmarshal(versesJson, &v); err != nil {
+		panic(err)
+	}
+	return v
+}()
+
+// fetchedVerse has the verse fetched for the day, or being fetched, nil
+// until it has been or when that failed.
+var fetchedVerse struct {
+	sync.Mutex
+	day   int
+	verse *Verse
+}
+
+// verseClient fetches the online verses.
+var verseClient = &http.Client{Timeout: 10 * time.Second}
+
+type Verse struct {
+	Arabic      string
+	Translation string
+	Source      string
+}
+
+// DailyVerse returns the verse or hadith for t's day, the same one all day
+// but for the online verse, which is fetched in the background and returned
+// once it has been.
+func DailyVerse(t time.Time) Verse {
+	y, m, d := t.Date()
+	day := int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / (24 * 60 * 60))
+
+	if onlineVerse {
+		fetchedVerse.Lock()
+		defer fetchedVerse.Unlock()
+		if fetchedVerse.day != day {
+			fetchedVerse.day, fetchedVerse.verse = day, nil
+			go func() {
+				v, err := FetchVerse(day%ayahCount + 1)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, "verse:", err)
+					return
+				}
+				fetchedVerse.Lock()
+				if fetchedVerse.day == day {
+					fetchedVerse.verse = &v
+				}
+				fetchedVerse.Unlock()
+			}()
+		}
+		if fetchedVerse.verse != nil {
+			return *fetchedVerse.verse
+		}
+	}
+	return verses[day%len(verses)]
+}
+
+// FetchVerse downloads ayah n, counting from the start of the Quran.
+func FetchVerse(n int) (Verse, error) {
+	resp, err := verseClient.Get(fmt.Sprintf(verseApiUrl, n))
+	if err != nil {
+		return Verse{}, err
+	}
+	defer resp.Body.Close()
+
+	var r struct {
+		Data []struct {
+			Text          string
+			NumberInSurah int
+			Surah         struct {
+				Number      int
+				EnglishName string
+			}
+		}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return Verse{}, err
+	}
+	if len(r.Data) != 2 {
+		return Verse{}, fmt.Errorf("unexpected response for ayah %d", n)
+	}
+
+	s := r.Data[0].Surah
+	return Verse{
+		Arabic:      r.Data[0].Text,
+		Translation: r.Data[1].Text,
+		Source:      fmt.Sprintf("%s %d:%d", s.EnglishName, s.Number, r.Data[0].NumberInSurah),
+	}, nil
+}
+
+func (v Verse) String() string {
+	return fmt.Sprintf("%s\n%s\n(%s)", v.Arabic, v.Translation, v.Source)
+}
@@ -0,0 +1,29 @@
+//go:build !gio
+
+package main
+
+import (
+	"time"
+
+	"github.com/gen2brain/iup-go/iup"
+)
+
+// versePanel returns a frame with the verse of the day, and a function to
+// update it, to another day's or the online verse once fetched.
+func versePanel() (iup.Ihandle, func(time.Time)) {
+	label := iup.Label("")
+	label.SetAttributes(map[string]string{
+		"WORDWRAP":  "YES",
+		"ALIGNMENT": "ACENTER",
+		"EXPAND":    "HORIZONTAL",
+	})
+
+	frame := iup.Frame(label)
+	iup.SetAttribute(frame, "TITLE", "Verse of the day")
+
+	update := func(t time.Time) {
+		setTitle(label, DailyVerse(t).String())
+	}
+	update(time.Now())
+	return frame, update
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Alert when the window of a prayer not yet marked as prayed is about to end.
+var windowAlert = struct {
+	Enabled bool
+	Before  Duration
+	Sound   string
+}{
+	Enabled: true,
+	Before:  Duration(15 * time.Minute),
+	Sound:   "tasbih.wav",
+}
+
+var showSinceAdhan = true
+
+// WindowEnd returns the time p's window ends: sunrise for Fajr, and the next
+// prayer for the others.
+func WindowEnd(loc Location, p Prayer) time.Time {
+	if p.Name == "Fajr" {
+		if sunrise, ok := DayTimings(loc, p.Time)["Sunrise"]; ok {
+			return sunrise
+		}
+	}
+
+	prayers := PrayerTimings(loc, p.Time)
+	for i, v := range prayers[:len(prayers)-1] {
+		if v.Name == p.Name {
+			return prayers[i+1].Time
+		}
+	}
+	return PrayerTimings(loc, p.Time.AddDate(0, 0, 1))[0].Time
+}
+
+func FormatWindow(p Prayer, rem time.Duration) string {
+	return fmt.Sprintf("%s ends %s", p.Label(), FormatUntil("in", time.Now().Add(rem)))
+}
+
+// FormatSince formats how long ago p's adhan was, in hours and minutes.
+func FormatSince(p Prayer, now time.Time) string {
+	since := now.Sub(p.Time)
+	h := since / time.Hour
+	m := (since - h*time.Hour) / time.Minute
+	return fmt.Sprintf("%s was %02d:%02d ago", p.Label(), h, m)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os"
+	"sort"
+)
+
+// methodRegion is a latitude and longitude box where a method is the usual
+// one.
+type methodRegion struct {
+	MinLat, MaxLat, MinLon, MaxLon float64
+	Method                         int
+}
+
+// methodRegions are tried in order, the smaller countries before the
+// regions around them.
+var methodRegions = []methodRegion{
+	{24.4, 26.2, 50.7, 51.7, 10}, // Qatar
+	{28.5, 30.1, 46.5, 48.5, 9},  // Kuwait
+	{22.6, 26.1, 51.5, 56.4, 16}, // United Arab Emirates
+	{1.1, 1.5, 103.6, 104.1, 11}, // Singapore
+	{29.2, 33.4, 34.9, 39.3, 23}, // Jordan
+	{16, 32.2, 34.5, 55.7, 4},    // Saudi Arabia
+	{16.5, 26.5, 51, 60, 8},      // the rest of the Gulf
+	{22, 31.7, 24.7, 35, 5},      // Egypt
+	{35.8, 42.1, 26, 44.8, 13},   // Turkey
+	{25, 39.8, 44, 63.3, 7},      // Iran
+	{30.2, 37.3, 7.5, 11.6, 18},  // Tunisia
+	{27.6, 35.9, -13.2, -1, 21},  // Morocco
+	{19, 37.1, -8.7, 12, 19},     // Algeria
+	{36.9, 42.2, -9.6, -6.2, 22}, // Portugal
+	{41.3, 51.1, -5.2, 9.6, 12},  // France
+	{0.8, 7.4, 99.6, 119.3, 17},  // Malaysia
+	{-11, 6, 95, 141, 20},        // Indonesia
+	{5, 37, 60, 97.5, 1},         // Pakistan, India and Bangladesh
+	{41, 82, 27, 180, 14},        // Russia
+	{14, 84, -170, -50, 2},       // North America
+	{-90, 90, -180, 180, 3},      // elsewhere
+}
+
+// SuggestMethod returns the calculation method usual at loc.
+func SuggestMethod(loc Location) int {
+	for _, r := range methodRegions {
+		if loc.Latitude >= r.MinLat && loc.Latitude <= r.MaxLat &&
+			loc.Longitude >= r.MinLon && loc.Longitude <= r.MaxLon {
+			return r.Method
+		}
+	}
+	return 3
+}
+
+// methodNumbers returns the numbers of calcMethods in order.
+func methodNumbers() []int {
+	var numbers []int
+	for n := range calcMethods {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	return numbers
+}
+
+// firstRun reports whether there is no config file yet.
+func firstRun() bool {
+	_, err := os.Stat(configPath)
+	return os.IsNotExist(err)
+}
+
+// FinishSetup applies the choices of the setup wizard and writes them to
+// the config file, along with the other defaults.
+func FinishSetup(loc Location, m int, sound string, autostart bool) error {
+	if err := ValidateLocation(loc); err != nil {
+		return err
+	}
+	location = loc
+	profiles[0] = loc
+	method = m
+	if sound != "" {
+		adhanSound = sound
+	}
+	if err := ValidateSettings(); err != nil {
+		return err
+	}
+
+	if autostart {
+		cmd, err := autostartCommandLine()
+		if err == nil {
+			err = EnableAutostart(cmd)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return SaveConfig()
+}
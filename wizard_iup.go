@@ -0,0 +1,184 @@
+//go:build !gio
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gen2brain/iup-go/iup"
+)
+
+// setupWizard asks for the location, calculation method, adhan sound and
+// autostart on the first run, and writes the config file. Skipping keeps
+// the defaults and asks again on the next start.
+func setupWizard() {
+	iup.Open()
+	initScale()
+	iup.SetGlobal("DEFAULTFONT", "Courier "+scaled(15))
+
+	field := func(value string) iup.Ihandle {
+		t := iup.Text()
+		t.SetAttributes(map[string]string{"VALUE": value, "VISIBLECOLUMNS": "20"})
+		return t
+	}
+	name := field(location.Name)
+	latitude := field(fmt.Sprint(location.Latitude))
+	longitude := field(fmt.Sprint(location.Longitude))
+
+	methods := iup.List()
+	methods.SetAttributes(map[string]string{"DROPDOWN": "YES", "VISIBLEITEMS": "12"})
+	numbers := methodNumbers()
+	selectMethod := func(m int) {
+		suggested := -1
+		if loc, err := parseLocation(name, latitude, longitude); err == nil {
+			suggested = SuggestMethod(loc)
+		}
+		for i, n := range numbers {
+			label := calcMethods[n].Name
+			if n == suggested {
+				label += " (suggested)"
+			}
+			iup.SetAttribute(methods, strconv.Itoa(i+1), label)
+			if n == m {
+				iup.SetAttribute(methods, "VALUE", i+1)
+			}
+		}
+	}
+	selectMethod(method)
+
+	// Moving the location suggests its method.
+	suggest := iup.KillFocusFunc(func(ih iup.Ihandle) int {
+		if loc, err := parseLocation(name, latitude, longitude); err == nil {
+			selectMethod(SuggestMethod(loc))
+		}
+		return iup.DEFAULT
+	})
+	iup.SetCallback(latitude, "KILLFOCUS_CB", suggest)
+	iup.SetCallback(longitude, "KILLFOCUS_CB", suggest)
+
+	errorLabel := iup.Label("")
+	errorLabel.SetAttributes(map[string]string{"FGCOLOR": "200 0 0", "EXPAND": "HORIZONTAL"})
+
+	detectButton := iup.Button("&Detect")
+	iup.SetAttribute(detectButton, "TIP", "Look up the location from the IP address or location services")
+	iup.SetCallback(detectButton, "ACTION", iup.ActionFunc(func(ih iup.Ihandle) int {
+		loc, err := detectLocation()
+		if err != nil {
+			setTitle(errorLabel, "Detection failed: "+err.Error())
+			return iup.DEFAULT
+		}
+		setTitle(errorLabel, "")
+		iup.SetAttribute(name, "VALUE", loc.Name)
+		iup.SetAttribute(latitude, "VALUE", fmt.Sprint(loc.Latitude))
+		iup.SetAttribute(longitude, "VALUE", fmt.Sprint(loc.Longitude))
+		selectMethod(SuggestMethod(loc))
+		return iup.DEFAULT
+	}))
+
+	sound := field(adhanSound)
+	browseButton := iup.Button("&Browse…")
+	iup.SetCallback(browseButton, "ACTION", iup.ActionFunc(func(ih iup.Ihandle) int {
+		fd := iup.FileDlg()
+		defer fd.Destroy()
+		fd.SetAttributes(map[string]string{
+			"DIALOGTYPE": "OPEN",
+			"TITLE":      "Adhan sound",
+			"FILTER":     "*.wav",
+			"FILTERINFO": "Wave sound",
+		})
+		iup.Popup(fd, iup.CENTER, iup.CENTER)
+		if fd.GetInt("STATUS") != -1 {
+			iup.SetAttribute(sound, "VALUE", fd.GetAttribute("VALUE"))
+		}
+		return iup.DEFAULT
+	}))
+
+	autostart := iup.Toggle("Start Prayer when logging in")
+
+	saveButton := iup.Button("&Save")
+	iup.SetAttribute(saveButton, "PADDING", pxSize(5, 5))
+	iup.SetCallback(saveButton, "ACTION", iup.ActionFunc(func(ih iup.Ihandle) int {
+		loc, err := parseLocation(name, latitude, longitude)
+		if err != nil {
+			setTitle(errorLabel, err.Error())
+			return iup.DEFAULT
+		}
+		m := numbers[iup.GetInt(methods, "VALUE")-1]
+		on := iup.GetAttribute(autostart, "VALUE") == "ON"
+		if err := FinishSetup(loc, m, iup.GetAttribute(sound, "VALUE"), on); err != nil {
+			setTitle(errorLabel, err.Error())
+			return iup.DEFAULT
+		}
+		return iup.CLOSE
+	}))
+	skipButton := iup.Button("S&kip")
+	iup.SetAttribute(skipButton, "PADDING", pxSize(5, 5))
+	iup.SetCallback(skipButton, "ACTION", iup.ActionFunc(func(ih iup.Ihandle) int {
+		return iup.CLOSE
+	}))
+
+	grid := func(children ...iup.Ihandle) iup.Ihandle {
+		g := iup.GridBox(children...)
+		g.SetAttributes(map[string]string{
+			"NUMDIV":       "2",
+			"ALIGNMENTLIN": "ACENTER",
+			"GAPLIN":       scaled(4),
+			"GAPCOL":       scaled(4),
+		})
+		return g
+	}
+	locationFrame := iup.Frame(iup.Vbox(
+		grid(iup.Label("Name"), name, iup.Label("Latitude"), latitude, iup.Label("Longitude"), longitude),
+		detectButton,
+	))
+	iup.SetAttribute(locationFrame, "TITLE", "Location")
+	methodFrame := iup.Frame(methods)
+	iup.SetAttribute(methodFrame, "TITLE", "Calculation method")
+	testSound := testButton(func() string { return iup.GetAttribute(sound, "VALUE") }, errorLabel)
+	soundFrame := iup.Frame(iup.Hbox(sound, browseButton, testSound, stopButton()))
+	iup.SetAttribute(soundFrame, "TITLE", "Adhan sound")
+
+	vbox := iup.Vbox(
+		iup.Label("Welcome to Prayer. Set where you are and how times are calculated."),
+		locationFrame, methodFrame, soundFrame, autostart, errorLabel,
+		iup.Hbox(iup.Fill(), skipButton, saveButton),
+	)
+	vbox.SetAttributes(map[string]string{
+		"MARGIN": pxSize(8, 8),
+		"GAP":    scaled(6),
+	})
+
+	dlg := iup.Dialog(vbox)
+	dlg.SetAttributes(map[string]string{
+		"TITLE":        "Prayer setup",
+		"RESIZE":       "NO",
+		"DEFAULTENTER": "saveButton",
+	})
+	iup.SetHandle("saveButton", saveButton)
+	iup.Popup(dlg, iup.CENTER, iup.CENTER)
+	StopSound()
+	dlg.Destroy()
+}
+
+// parseLocation reads the location fields.
+func parseLocation(name, latitude, longitude iup.Ihandle) (Location, error) {
+	loc := Location{Name: strings.TrimSpace(iup.GetAttribute(name, "VALUE"))}
+	if loc.Name == "" {
+		return loc, errors.New("Enter a name for the location")
+	}
+	var err error
+	for _, f := range []struct {
+		ih    iup.Ihandle
+		label string
+		v     *float64
+	}{{latitude, "latitude", &loc.Latitude}, {longitude, "longitude", &loc.Longitude}} {
+		s := strings.TrimSpace(iup.GetAttribute(f.ih, "VALUE"))
+		if *f.v, err = strconv.ParseFloat(s, 64); err != nil {
+			return loc, fmt.Errorf("The %s %q is not a number", f.label, s)
+		}
+	}
+	return loc, nil
+}